@@ -118,6 +118,18 @@ func (ci *Ticks) binanceIntegrationTests(
 	return c
 }
 
+// sqlIntegrationTests runs the SQL database integration tests with a Postgres service.
+func (ci *Ticks) sqlIntegrationTests(sourceDir *dagger.Directory) *dagger.Container {
+	db := PostgresService(dag, sourceDir)
+
+	c := dag.Container().From("golang:" + goVersion() + "-alpine")
+	c = ci.withGoCodeAndCacheAsWorkDirectory(c, sourceDir).
+		WithServiceBinding("postgres", db).
+		WithEnvVariable("SQL_DSN", "host=postgres user=cryptellation password=cryptellation dbname=ticks sslmode=disable").
+		WithExec([]string{"sh", "-c", "go test -v -tags=integration ./svc/db/... | grep -v 'no test files'"})
+	return c
+}
+
 // IntegrationTests returns all integration test containers for this service.
 func (ci *Ticks) IntegrationTests(
 	sourceDir *dagger.Directory,
@@ -126,6 +138,7 @@ func (ci *Ticks) IntegrationTests(
 ) []*dagger.Container {
 	return []*dagger.Container{
 		ci.binanceIntegrationTests(sourceDir, binanceApiKey, binanceSecretKey),
+		ci.sqlIntegrationTests(sourceDir),
 	}
 }
 
@@ -146,7 +159,7 @@ func (ci *Ticks) EndToEndTests(
 	exchanges := ExchangesService(dag, sourceDir, db, temporal, binanceApiKey, binanceSecretKey)
 
 	// Start Ticks service and bind it to the test container
-	ticks := Runner(dag, sourceDir, temporal, binanceApiKey, binanceSecretKey)
+	ticks := Runner(dag, sourceDir, db, temporal, binanceApiKey, binanceSecretKey)
 
 	c := dag.Container().From("golang:" + goVersion() + "-alpine")
 	c = ci.withGoCodeAndCacheAsWorkDirectory(c, sourceDir).
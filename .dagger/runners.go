@@ -35,10 +35,11 @@ func AvailablePlatforms() []string {
 }
 
 // Runner returns a container running the ticks service built from the official Dockerfile,
-// with its own Postgres and a given Temporal service.
+// with given Postgres and Temporal services.
 func Runner(
 	_ *dagger.Client,
 	sourceDir *dagger.Directory,
+	db *dagger.Service,
 	temporal *dagger.Service,
 	binanceAPIKey *dagger.Secret,
 	binanceSecretKey *dagger.Secret,
@@ -70,6 +71,13 @@ func Runner(
 		Dockerfile: "build/container/Dockerfile",
 	})
 
+	// Bind the Postgres service to the container
+	container = container.WithServiceBinding("postgres", db)
+	container = container.WithEnvVariable(
+		"SQL_DSN",
+		"host=postgres user=cryptellation password=cryptellation dbname=ticks sslmode=disable",
+	)
+
 	// Bind the Temporal service to the container
 	container = container.WithServiceBinding("temporal", temporal)
 	container = container.WithEnvVariable("TEMPORAL_ADDRESS", "temporal:7233")
@@ -78,10 +86,16 @@ func Runner(
 	container = container.WithSecretVariable("BINANCE_API_KEY", binanceAPIKey)
 	container = container.WithSecretVariable("BINANCE_SECRET_KEY", binanceSecretKey)
 
+	// Enable the simulated exchange for offline tests
+	container = container.WithEnvVariable("SIMULATED_ENABLED", "true")
+
 	// Expose the default port (9000) as in Dockerfile
 	container = container.WithExposedPort(9000)
 
 	return container.AsService(dagger.ContainerAsServiceOpts{
-		Args: []string{"worker", "serve"},
+		Args: []string{"sh", "-c", `
+			worker database migrate
+			worker serve
+		`},
 	})
 }
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/runtime"
+)
+
+var (
+	// ErrUnknownOverflowPolicy is the error when the overflow policy is unknown.
+	ErrUnknownOverflowPolicy = errors.New("unknown overflow policy")
+	// ErrInvalidDeliveryOptions is the error when the delivery options are invalid.
+	ErrInvalidDeliveryOptions = errors.New("invalid delivery options")
+	// ErrUnknownFailureAction is the error when the failure action is unknown.
+	ErrUnknownFailureAction = errors.New("unknown failure action")
+)
+
+const (
+	// DefaultBufferSize is the default count of ticks that can wait for
+	// delivery to a listener.
+	DefaultBufferSize = 100
+
+	// DefaultBatchMaxLatency is the default maximum time a tick waits for its
+	// batch to be full before the batch is delivered.
+	DefaultBatchMaxLatency = time.Second
+
+	// DefaultFailurePauseDuration is the default time the deliveries to a
+	// listener are paused for when it has failed too many times in a row.
+	DefaultFailurePauseDuration = time.Minute
+
+	// DefaultTicksSignalName is the default name of the signal used to deliver
+	// ticks to a workflow.
+	DefaultTicksSignalName = "TicksSignal"
+
+	// MaxBlockDuration is the maximum time a listener with the
+	// OverflowPolicyBlock policy keeps ticks over its buffer size, waiting for
+	// its deliveries to make room for them.
+	MaxBlockDuration = 10 * time.Second
+)
+
+// SignalTarget is a running workflow to which the ticks are delivered as
+// signals, instead of starting a callback workflow for each delivery.
+// The signals carry a ListenToTicksCallbackWorkflowParams, or a
+// ListenToTicksBatchCallbackWorkflowParams if the batching is enabled.
+type SignalTarget struct {
+	WorkflowID string
+	// SignalName is the name of the signals sent to the workflow.
+	// Defaults to DefaultTicksSignalName if empty.
+	SignalName string
+}
+
+// IsSet returns true if the ticks are delivered as signals.
+func (t SignalTarget) IsSet() bool {
+	return t.WorkflowID != ""
+}
+
+// WithDefaults returns the target with the default values set.
+func (t SignalTarget) WithDefaults() SignalTarget {
+	if t.IsSet() && t.SignalName == "" {
+		t.SignalName = DefaultTicksSignalName
+	}
+	return t
+}
+
+// OverflowPolicy is the behavior of a listener buffer when a tick is received
+// while it is full.
+type OverflowPolicy string
+
+const (
+	// OverflowPolicyDropOldest drops the oldest buffered tick to keep the new one.
+	OverflowPolicyDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowPolicyDropNewest drops the new tick and keeps the buffered ones.
+	OverflowPolicyDropNewest OverflowPolicy = "drop_newest"
+	// OverflowPolicyConflate replaces the newest buffered tick by the new one.
+	OverflowPolicyConflate OverflowPolicy = "conflate"
+	// OverflowPolicyBlock keeps the new tick over the buffer size until the
+	// listener has room for it, without slowing down the other listeners of
+	// the same sentry. The oldest buffered ticks are dropped if there is still
+	// no room after MaxBlockDuration. It cannot be used with the
+	// FailureActionPause action.
+	OverflowPolicyBlock OverflowPolicy = "block"
+)
+
+// OverflowPolicies is the list of the overflow policies.
+var OverflowPolicies = []OverflowPolicy{
+	OverflowPolicyDropOldest,
+	OverflowPolicyDropNewest,
+	OverflowPolicyConflate,
+	OverflowPolicyBlock,
+}
+
+// OrDefault returns the policy or the default policy (drop oldest) if it is empty.
+func (p OverflowPolicy) OrDefault() OverflowPolicy {
+	if p == "" {
+		return OverflowPolicyDropOldest
+	}
+	return p
+}
+
+// Validate returns an error if the policy is not empty and not a known policy.
+func (p OverflowPolicy) Validate() error {
+	if p == "" {
+		return nil
+	}
+
+	for _, policy := range OverflowPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownOverflowPolicy, p)
+}
+
+// FailureAction is the action taken on a listener when its deliveries have
+// failed too many times in a row.
+type FailureAction string
+
+const (
+	// FailureActionEvict removes the listener from the sentry.
+	FailureActionEvict FailureAction = "evict"
+	// FailureActionPause stops the deliveries for a while, then tries again.
+	// The ticks received in the meantime are buffered with the overflow policy.
+	FailureActionPause FailureAction = "pause"
+)
+
+// FailureActions is the list of the failure actions.
+var FailureActions = []FailureAction{
+	FailureActionEvict,
+	FailureActionPause,
+}
+
+// OrDefault returns the action or the default action (evict) if it is empty.
+func (a FailureAction) OrDefault() FailureAction {
+	if a == "" {
+		return FailureActionEvict
+	}
+	return a
+}
+
+// Validate returns an error if the action is not empty and not a known action.
+func (a FailureAction) Validate() error {
+	if a == "" {
+		return nil
+	}
+
+	for _, action := range FailureActions {
+		if a == action {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownFailureAction, a)
+}
+
+// RetryPolicy is the retry policy of the callback workflow of each delivery.
+// The retries are bound by the callback execution timeout.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum count of attempts of each delivery.
+	// The deliveries are not retried if it is lower than 2.
+	MaxAttempts int32
+	// InitialInterval is the interval before the first retry.
+	// Defaults to one second if empty.
+	InitialInterval time.Duration
+	// BackoffCoefficient is the coefficient applied to the interval after each
+	// retry. Defaults to 2 if empty.
+	BackoffCoefficient float64
+	// MaxInterval is the maximum interval between two retries.
+	// Defaults to 100 times the initial interval if empty.
+	MaxInterval time.Duration
+}
+
+// Enabled returns true if the deliveries are retried.
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// WithDefaults returns the policy with the default values set, if enabled.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if !p.Enabled() {
+		return p
+	}
+
+	if p.InitialInterval == 0 {
+		p.InitialInterval = time.Second
+	}
+	if p.BackoffCoefficient == 0 {
+		p.BackoffCoefficient = 2
+	}
+	if p.MaxInterval == 0 {
+		p.MaxInterval = 100 * p.InitialInterval
+	}
+	return p
+}
+
+// FailurePolicy is the behavior of a listener when its deliveries fail.
+type FailurePolicy struct {
+	// MaxConsecutiveFailures is the count of deliveries failing in a row after
+	// which the action is taken. The deliveries go on despite the failures if
+	// it is empty.
+	MaxConsecutiveFailures uint
+	// Action is the action taken after too many failures.
+	// Defaults to FailureActionEvict if empty.
+	Action FailureAction
+	// PauseDuration is the time the deliveries are paused for with the
+	// FailureActionPause action. Defaults to DefaultFailurePauseDuration if empty.
+	PauseDuration time.Duration
+	// Retry is the retry policy of each delivery. A delivery only counts as
+	// failed once its retries are exhausted. It cannot be used with signals.
+	Retry RetryPolicy
+}
+
+// SubscriptionEndReason is the reason why a subscription has been ended by
+// the service.
+type SubscriptionEndReason string
+
+const (
+	// SubscriptionEndReasonFailures is when the deliveries failed too many
+	// times in a row with the FailureActionEvict action.
+	SubscriptionEndReasonFailures SubscriptionEndReason = "max_consecutive_failures"
+	// SubscriptionEndReasonTimeout is when a callback workflow timed out.
+	SubscriptionEndReasonTimeout SubscriptionEndReason = "timeout"
+	// SubscriptionEndReasonTargetNotFound is when the workflow receiving the
+	// ticks as signals does not exist anymore.
+	SubscriptionEndReasonTargetNotFound SubscriptionEndReason = "target_not_found"
+)
+
+// BatchOptions are the options of the delivery of ticks by batches.
+type BatchOptions struct {
+	// MaxSize is the maximum count of ticks in a batch. Batching is enabled
+	// when it is set.
+	MaxSize uint
+	// MaxLatency is the maximum time a tick waits for its batch to be full
+	// before the batch is delivered. Defaults to DefaultBatchMaxLatency if empty.
+	MaxLatency time.Duration
+}
+
+// Enabled returns true if the ticks are delivered by batches.
+func (o BatchOptions) Enabled() bool {
+	return o.MaxSize > 0
+}
+
+// DeliveryOptions are the options of the ticks delivery to a listener.
+type DeliveryOptions struct {
+	// Conflate only keeps the most recent tick while the callback is
+	// executing, and delivers it next. It is a shortcut for a buffer of one
+	// tick with the OverflowPolicyConflate policy.
+	Conflate bool
+	// BufferSize is the count of ticks that can wait for delivery while the
+	// callback is executing. Defaults to DefaultBufferSize if empty.
+	BufferSize uint
+	// OverflowPolicy is the behavior when the buffer is full.
+	// Defaults to OverflowPolicyDropOldest if empty.
+	OverflowPolicy OverflowPolicy
+	// DeliverGaps delivers the gap ticks, marking an interruption of the
+	// exchange feed during which ticks may be missing. They have no price, so
+	// the callback must check Tick.IsGap before using them.
+	DeliverGaps bool
+	// SampleInterval is the minimum interval between two delivered ticks, if
+	// set. Only the last tick of each interval is delivered, at its end.
+	// Delivered gap ticks are not sampled.
+	SampleInterval time.Duration
+	// MinPriceChange is the absolute price change since the last delivered
+	// tick under which ticks are not delivered, if set.
+	MinPriceChange float64
+	// MinPriceChangeBps is the relative price change (in basis points) since
+	// the last delivered tick under which ticks are not delivered, if set.
+	// When both thresholds are set, a tick exceeding one of them is delivered.
+	// The tick following a gap is always delivered.
+	MinPriceChangeBps float64
+	// Batch delivers the ticks by batches to a callback taking
+	// ListenToTicksBatchCallbackWorkflowParams, if enabled.
+	Batch BatchOptions
+	// Failures is the behavior when the deliveries fail.
+	Failures FailurePolicy
+	// EndedCallback is the workflow executed with a
+	// SubscriptionEndedCallbackWorkflowParams when the service ends the
+	// subscription, if set.
+	EndedCallback runtime.CallbackWorkflow
+}
+
+// WithDefaults returns the options with the default values set.
+func (o DeliveryOptions) WithDefaults() DeliveryOptions {
+	if o.Conflate {
+		o.BufferSize = 1
+		o.OverflowPolicy = OverflowPolicyConflate
+	}
+
+	if o.BufferSize == 0 {
+		o.BufferSize = max(DefaultBufferSize, o.Batch.MaxSize)
+	}
+	if o.Batch.Enabled() && o.Batch.MaxLatency == 0 {
+		o.Batch.MaxLatency = DefaultBatchMaxLatency
+	}
+	o.OverflowPolicy = o.OverflowPolicy.OrDefault()
+
+	o.Failures.Action = o.Failures.Action.OrDefault()
+	if o.Failures.Action == FailureActionPause && o.Failures.PauseDuration == 0 {
+		o.Failures.PauseDuration = DefaultFailurePauseDuration
+	}
+	o.Failures.Retry = o.Failures.Retry.WithDefaults()
+	return o
+}
+
+// Validate returns an error if the options are invalid.
+func (o DeliveryOptions) Validate() error {
+	if err := o.OverflowPolicy.Validate(); err != nil {
+		return err
+	}
+	if o.SampleInterval < 0 {
+		return fmt.Errorf("%w: negative sample interval", ErrInvalidDeliveryOptions)
+	}
+	if o.MinPriceChange < 0 || o.MinPriceChangeBps < 0 {
+		return fmt.Errorf("%w: negative price change threshold", ErrInvalidDeliveryOptions)
+	}
+
+	// Check that the batches fit in the buffer
+	if o.Batch.MaxLatency < 0 {
+		return fmt.Errorf("%w: negative batch latency", ErrInvalidDeliveryOptions)
+	}
+	if o.Batch.Enabled() && (o.Conflate || (o.BufferSize > 0 && o.BufferSize < o.Batch.MaxSize)) {
+		return fmt.Errorf("%w: the buffer must be able to hold a whole batch", ErrInvalidDeliveryOptions)
+	}
+
+	// Check the failure policy
+	if err := o.Failures.Action.Validate(); err != nil {
+		return err
+	}
+	if o.Failures.PauseDuration < 0 {
+		return fmt.Errorf("%w: negative pause duration", ErrInvalidDeliveryOptions)
+	}
+	if o.Failures.Action == FailureActionPause && o.OverflowPolicy == OverflowPolicyBlock {
+		return fmt.Errorf("%w: a paused listener cannot block its buffer", ErrInvalidDeliveryOptions)
+	}
+	if r := o.Failures.Retry; r.InitialInterval < 0 || r.MaxInterval < 0 || r.MaxAttempts < 0 {
+		return fmt.Errorf("%w: negative retry policy value", ErrInvalidDeliveryOptions)
+	}
+	if r := o.Failures.Retry; r.BackoffCoefficient != 0 && r.BackoffCoefficient < 1 {
+		return fmt.Errorf("%w: retry backoff coefficient lower than 1", ErrInvalidDeliveryOptions)
+	}
+
+	// Check that the conflation does not contradict the buffer options
+	if o.Conflate && o.BufferSize > 1 {
+		return fmt.Errorf("%w: conflation requires a buffer of one tick", ErrInvalidDeliveryOptions)
+	}
+	if o.Conflate && o.OverflowPolicy != "" && o.OverflowPolicy != OverflowPolicyConflate {
+		return fmt.Errorf("%w: conflation is incompatible with the %q overflow policy",
+			ErrInvalidDeliveryOptions, o.OverflowPolicy)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+//go:build unit
+// +build unit
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestDeliverySuite(t *testing.T) {
+	suite.Run(t, new(DeliverySuite))
+}
+
+type DeliverySuite struct {
+	suite.Suite
+}
+
+func (suite *DeliverySuite) TestWithDefaults() {
+	o := DeliveryOptions{}.WithDefaults()
+	suite.Require().Equal(uint(DefaultBufferSize), o.BufferSize)
+	suite.Require().Equal(OverflowPolicyDropOldest, o.OverflowPolicy)
+
+	o = DeliveryOptions{Conflate: true}.WithDefaults()
+	suite.Require().Equal(uint(1), o.BufferSize)
+	suite.Require().Equal(OverflowPolicyConflate, o.OverflowPolicy)
+
+	o = DeliveryOptions{Batch: BatchOptions{MaxSize: 500}}.WithDefaults()
+	suite.Require().Equal(uint(500), o.BufferSize)
+	suite.Require().Equal(DefaultBatchMaxLatency, o.Batch.MaxLatency)
+	suite.Require().Equal(FailureActionEvict, o.Failures.Action)
+	suite.Require().Zero(o.Failures.PauseDuration)
+
+	o = DeliveryOptions{Failures: FailurePolicy{Action: FailureActionPause}}.WithDefaults()
+	suite.Require().Equal(DefaultFailurePauseDuration, o.Failures.PauseDuration)
+
+	o = DeliveryOptions{Failures: FailurePolicy{Retry: RetryPolicy{MaxAttempts: 3}}}.WithDefaults()
+	suite.Require().Equal(time.Second, o.Failures.Retry.InitialInterval)
+	suite.Require().Equal(2.0, o.Failures.Retry.BackoffCoefficient)
+	suite.Require().Equal(100*time.Second, o.Failures.Retry.MaxInterval)
+}
+
+func (suite *DeliverySuite) TestValidate() {
+	suite.Require().NoError(DeliveryOptions{}.Validate())
+	suite.Require().NoError(DeliveryOptions{Conflate: true, BufferSize: 1}.Validate())
+	suite.Require().NoError(DeliveryOptions{Conflate: true, OverflowPolicy: OverflowPolicyConflate}.Validate())
+	suite.Require().ErrorIs(DeliveryOptions{OverflowPolicy: "unknown"}.Validate(), ErrUnknownOverflowPolicy)
+	suite.Require().ErrorIs(DeliveryOptions{SampleInterval: -time.Second}.Validate(), ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{MinPriceChangeBps: -1}.Validate(), ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{Conflate: true, BufferSize: 2}.Validate(), ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{
+		Conflate:       true,
+		OverflowPolicy: OverflowPolicyBlock,
+	}.Validate(), ErrInvalidDeliveryOptions)
+
+	suite.Require().NoError(DeliveryOptions{Batch: BatchOptions{MaxSize: 10, MaxLatency: time.Second}}.Validate())
+	suite.Require().ErrorIs(DeliveryOptions{Batch: BatchOptions{MaxLatency: -time.Second}}.Validate(),
+		ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{BufferSize: 5, Batch: BatchOptions{MaxSize: 10}}.Validate(),
+		ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{Conflate: true, Batch: BatchOptions{MaxSize: 10}}.Validate(),
+		ErrInvalidDeliveryOptions)
+
+	suite.Require().NoError(DeliveryOptions{Failures: FailurePolicy{
+		MaxConsecutiveFailures: 3,
+		Action:                 FailureActionPause,
+		Retry:                  RetryPolicy{MaxAttempts: 3, InitialInterval: time.Second},
+	}}.Validate())
+	suite.Require().ErrorIs(DeliveryOptions{Failures: FailurePolicy{Action: "unknown"}}.Validate(),
+		ErrUnknownFailureAction)
+	suite.Require().ErrorIs(DeliveryOptions{Failures: FailurePolicy{PauseDuration: -time.Second}}.Validate(),
+		ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{
+		OverflowPolicy: OverflowPolicyBlock,
+		Failures:       FailurePolicy{Action: FailureActionPause},
+	}.Validate(), ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{Failures: FailurePolicy{
+		Retry: RetryPolicy{MaxInterval: -time.Second},
+	}}.Validate(), ErrInvalidDeliveryOptions)
+	suite.Require().ErrorIs(DeliveryOptions{Failures: FailurePolicy{
+		Retry: RetryPolicy{MaxAttempts: 3, BackoffCoefficient: 0.5},
+	}}.Validate(), ErrInvalidDeliveryOptions)
+}
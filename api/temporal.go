@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/google/uuid"
@@ -24,7 +26,15 @@ type (
 		RequesterID uuid.UUID
 		Exchange    string
 		Pair        string
-		Callback    runtime.CallbackWorkflow
+		// Kind is the kind of ticks to listen to (book or trade).
+		// Defaults to book ticks if empty.
+		Kind     tick.Kind
+		Callback runtime.CallbackWorkflow
+		// Signal delivers the ticks as signals to a running workflow, instead
+		// of the callback.
+		Signal SignalTarget
+		// Delivery is the options of the ticks delivery to the callback.
+		Delivery DeliveryOptions
 	}
 
 	// ListenToTicksCallbackWorkflowParams is the parameters of the
@@ -32,6 +42,32 @@ type (
 	ListenToTicksCallbackWorkflowParams struct {
 		RequesterID uuid.UUID
 		Tick        tick.Tick
+		// Dropped is the count of ticks dropped for this listener since the
+		// previous callback, because its buffer was full.
+		Dropped uint64
+	}
+
+	// ListenToTicksBatchCallbackWorkflowParams is the parameters of the
+	// RegisterForTicksListening callback workflow when the ticks are delivered
+	// by batches.
+	ListenToTicksBatchCallbackWorkflowParams struct {
+		RequesterID uuid.UUID
+		Ticks       []tick.Tick
+		// Dropped is the count of ticks dropped for this listener since the
+		// previous callback, because its buffer was full.
+		Dropped uint64
+	}
+
+	// SubscriptionEndedCallbackWorkflowParams is the parameters of the
+	// callback workflow executed when the service ends a subscription.
+	SubscriptionEndedCallbackWorkflowParams struct {
+		RequesterID uuid.UUID
+		Exchange    string
+		Pair        string
+		Kind        tick.Kind
+		Reason      SubscriptionEndReason
+		// Error is the error of the last failed delivery.
+		Error string
 	}
 
 	// RegisterForTicksListeningWorkflowResults is the results of the
@@ -53,6 +89,9 @@ type (
 		RequesterID uuid.UUID
 		Exchange    string
 		Pair        string
+		// Kind is the kind of ticks to stop listening to.
+		// Stops listening to all kinds if empty.
+		Kind tick.Kind
 	}
 
 	// UnregisterFromTicksListeningWorkflowResults is the results of the
@@ -60,6 +99,137 @@ type (
 	UnregisterFromTicksListeningWorkflowResults struct{}
 )
 
+const (
+	// GetTicksWorkflowName is the name of the workflow to get recorded ticks.
+	GetTicksWorkflowName = "GetTicksWorkflow"
+
+	// GetTicksMaxLimit is the maximum number of ticks returned by a single
+	// GetTicks workflow execution.
+	GetTicksMaxLimit = 1000
+)
+
+type (
+	// GetTicksWorkflowParams is the parameters of the GetTicks workflow.
+	GetTicksWorkflowParams struct {
+		Exchange string
+		Pair     string
+		// Kind is the kind of ticks to get (book or trade).
+		// Defaults to book ticks if empty. Gap ticks of the same feed are always included.
+		Kind  tick.Kind
+		Start time.Time
+		// End defaults to the current time if empty.
+		End time.Time
+		// Limit defaults to (and is capped at) GetTicksMaxLimit if empty.
+		Limit uint
+		// Cursor is the NextCursor of a previous result to get the next page.
+		Cursor string
+	}
+
+	// GetTicksWorkflowResults is the results of the GetTicks workflow.
+	GetTicksWorkflowResults struct {
+		Ticks []tick.Tick
+		// NextCursor is the cursor to get the next page, empty if there is none.
+		NextCursor string
+	}
+)
+
+const (
+	// GetLastTickWorkflowName is the name of the workflow to get the last known tick.
+	GetLastTickWorkflowName = "GetLastTickWorkflow"
+
+	// DefaultLastTickMaxAge is the default maximum age of the tick returned
+	// by a running listener or from the recorded ticks.
+	DefaultLastTickMaxAge = time.Minute
+)
+
+// LastTickSource is the source of the last known tick.
+type LastTickSource string
+
+const (
+	// LastTickSourceListener is when the tick comes from a running listener.
+	LastTickSourceListener LastTickSource = "listener"
+	// LastTickSourceDatabase is when the tick comes from the recorded ticks.
+	LastTickSourceDatabase LastTickSource = "database"
+	// LastTickSourceExchange is when the tick has been fetched from the exchange.
+	LastTickSourceExchange LastTickSource = "exchange"
+)
+
+type (
+	// GetLastTickWorkflowParams is the parameters of the GetLastTick workflow.
+	GetLastTickWorkflowParams struct {
+		Exchange string
+		Pair     string
+		// Kind is the kind of tick to get (book or trade).
+		// Defaults to book ticks if empty.
+		Kind tick.Kind
+		// MaxAge is the maximum age of the tick returned by a running listener or
+		// from the recorded ticks. Older ticks are fetched again from the exchange.
+		// Defaults to DefaultLastTickMaxAge if empty.
+		MaxAge time.Duration
+	}
+
+	// GetLastTickWorkflowResults is the results of the GetLastTick workflow.
+	GetLastTickWorkflowResults struct {
+		Tick   tick.Tick
+		Source LastTickSource
+	}
+)
+
+const (
+	// ListSubscriptionsWorkflowName is the name of the workflow to list the
+	// running sentries and their subscriptions.
+	ListSubscriptionsWorkflowName = "ListSubscriptionsWorkflow"
+)
+
+type (
+	// ListSubscriptionsWorkflowParams is the parameters of the ListSubscriptions workflow.
+	ListSubscriptionsWorkflowParams struct {
+		// Exchange filters the sentries on the exchange, if set.
+		Exchange string
+		// Pair filters the sentries on the pair, if set.
+		Pair string
+	}
+
+	// ListSubscriptionsWorkflowResults is the results of the ListSubscriptions workflow.
+	ListSubscriptionsWorkflowResults struct {
+		Sentries []SentryInfo
+	}
+
+	// SentryInfo is the information of a running sentry, which listens to an
+	// exchange pair and sends the ticks to its subscriptions.
+	SentryInfo struct {
+		WorkflowID    string
+		StartTime     time.Time
+		Exchange      string
+		Pair          string
+		Kind          tick.Kind
+		Subscriptions []Subscription
+	}
+
+	// Subscription is a callback workflow registered on a sentry.
+	Subscription struct {
+		RequesterID  uuid.UUID
+		CallbackName string
+		TaskQueue    string
+		Signal       SignalTarget
+		RegisteredAt time.Time
+		Delivery     DeliveryOptions
+		// Delivered is the count of ticks successfully sent to the callback.
+		Delivered uint64
+		// Failed is the count of ticks that failed to be sent to the callback.
+		Failed uint64
+		// Dropped is the count of ticks dropped because the buffer was full.
+		Dropped uint64
+		// Queued is the count of ticks waiting to be sent to the callback.
+		Queued int
+		// ConsecutiveFailures is the count of deliveries that failed in a row.
+		ConsecutiveFailures uint
+		// PausedUntil is the end of the pause of the deliveries after too
+		// many failures, if any.
+		PausedUntil time.Time
+	}
+)
+
 const (
 	// ServiceInfoWorkflowName is the name of the workflow to get the service info.
 	ServiceInfoWorkflowName = "ServiceInfoWorkflow"
@@ -74,3 +244,85 @@ type (
 		Version string
 	}
 )
+
+const (
+	// ListDeadLettersWorkflowName is the name of the workflow to list the
+	// deliveries of ticks that have failed.
+	ListDeadLettersWorkflowName = "ListDeadLettersWorkflow"
+
+	// ListDeadLettersMaxLimit is the maximum number of dead letters returned
+	// by a single ListDeadLetters workflow execution.
+	ListDeadLettersMaxLimit = 1000
+
+	// RedeliverDeadLettersWorkflowName is the name of the workflow to deliver
+	// again the ticks whose delivery has failed.
+	RedeliverDeadLettersWorkflowName = "RedeliverDeadLettersWorkflow"
+)
+
+type (
+	// DeadLetter is a delivery of ticks to a requester that has failed.
+	DeadLetter struct {
+		ID int64
+		// DeliveryID identifies the failed delivery, which is only recorded
+		// once. It is the ID of the delivery workflow for callbacks.
+		DeliveryID  string
+		RequesterID uuid.UUID
+		Exchange    string
+		Pair        string
+		Kind        tick.Kind
+		// Callback is the callback workflow the ticks were sent to, if any.
+		Callback runtime.CallbackWorkflow
+		// Signal is the workflow the ticks were signaled to, if any.
+		Signal SignalTarget
+		// Batch is true if the ticks were delivered as a batch.
+		Batch bool
+		Ticks []tick.Tick
+		// Error is the error of the last failed delivery.
+		Error string
+		// Attempts is the count of attempts of the delivery: the maximum
+		// attempts of the retry policy (or one, as for signals) for the first
+		// delivery, plus one for each redelivery.
+		Attempts int
+		FailedAt time.Time
+		// RedeliveredAt is the time of the successful redelivery, if any. It is
+		// only set once the delivery is confirmed: a redelivery conflicting with
+		// a delivery still in progress fails and can be retried later.
+		RedeliveredAt *time.Time
+	}
+
+	// ListDeadLettersWorkflowParams is the parameters of the ListDeadLetters workflow.
+	ListDeadLettersWorkflowParams struct {
+		// RequesterID filters the dead letters on the requester, if set.
+		RequesterID uuid.UUID
+		// Exchange filters the dead letters on the exchange, if set.
+		Exchange string
+		// Pair filters the dead letters on the pair, if set.
+		Pair string
+		// IncludeRedelivered also lists the dead letters that have been
+		// successfully redelivered.
+		IncludeRedelivered bool
+		// AfterID lists the dead letters after this one, to get the next page.
+		AfterID int64
+		// Limit defaults to (and is capped at) ListDeadLettersMaxLimit if empty.
+		Limit uint
+	}
+
+	// ListDeadLettersWorkflowResults is the results of the ListDeadLetters workflow.
+	ListDeadLettersWorkflowResults struct {
+		// DeadLetters are ordered by ID.
+		DeadLetters []DeadLetter
+	}
+
+	// RedeliverDeadLettersWorkflowParams is the parameters of the RedeliverDeadLetters workflow.
+	RedeliverDeadLettersWorkflowParams struct {
+		IDs []int64
+	}
+
+	// RedeliverDeadLettersWorkflowResults is the results of the RedeliverDeadLetters workflow.
+	RedeliverDeadLettersWorkflowResults struct {
+		// DeadLetters are the requested dead letters after the redelivery: the
+		// redelivered ones have their RedeliveredAt set, and the other ones
+		// their Error and Attempts updated.
+		DeadLetters []DeadLetter
+	}
+)
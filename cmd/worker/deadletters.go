@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/clients"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deadLettersRequesterFlag          string
+	deadLettersExchangeFlag           string
+	deadLettersPairFlag               string
+	deadLettersIncludeRedeliveredFlag bool
+	deadLettersAfterFlag              int64
+	deadLettersLimitFlag              uint
+)
+
+var (
+	ticksClient clients.Client
+)
+
+var deadLettersCmd = &cobra.Command{
+	Use:     "deadletters",
+	Aliases: []string{"dl"},
+	Short:   "Inspect and redeliver the ticks whose delivery has failed",
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		temporalClient, err := createTemporalClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		ticksClient = clients.New(temporalClient)
+		return nil
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		ticksClient.TemporalClient().Close()
+	},
+}
+
+var listDeadLettersCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l"},
+	Short:   "List the failed deliveries",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		params := api.ListDeadLettersWorkflowParams{
+			Exchange:           deadLettersExchangeFlag,
+			Pair:               deadLettersPairFlag,
+			IncludeRedelivered: deadLettersIncludeRedeliveredFlag,
+			AfterID:            deadLettersAfterFlag,
+			Limit:              deadLettersLimitFlag,
+		}
+
+		// Parse the requester ID, if set
+		if deadLettersRequesterFlag != "" {
+			id, err := uuid.Parse(deadLettersRequesterFlag)
+			if err != nil {
+				return err
+			}
+			params.RequesterID = id
+		}
+
+		res, err := ticksClient.ListDeadLetters(cmd.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(cmd, res.DeadLetters)
+	},
+}
+
+var redeliverDeadLettersCmd = &cobra.Command{
+	Use:     "redeliver <id>...",
+	Aliases: []string{"r"},
+	Short:   "Redeliver failed deliveries",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Parse the dead letters IDs
+		ids := make([]int64, 0, len(args))
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+
+		res, err := ticksClient.RedeliverDeadLetters(cmd.Context(), api.RedeliverDeadLettersWorkflowParams{
+			IDs: ids,
+		})
+		if err != nil {
+			return err
+		}
+
+		return printJSON(cmd, res.DeadLetters)
+	},
+}
+
+func printJSON(cmd *cobra.Command, v any) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func addDeadLettersCommands(cmd *cobra.Command) {
+	deadLettersCmd.AddCommand(listDeadLettersCmd)
+	deadLettersCmd.AddCommand(redeliverDeadLettersCmd)
+
+	// Set flags
+	flags := listDeadLettersCmd.Flags()
+	flags.StringVarP(&deadLettersRequesterFlag, "requester", "r", "", "Filter on the requester ID")
+	flags.StringVarP(&deadLettersExchangeFlag, "exchange", "e", "", "Filter on the exchange")
+	flags.StringVarP(&deadLettersPairFlag, "pair", "p", "", "Filter on the pair")
+	flags.BoolVarP(&deadLettersIncludeRedeliveredFlag, "all", "a", false, "Include the redelivered ones")
+	flags.Int64Var(&deadLettersAfterFlag, "after", 0, "List the ones after this ID")
+	flags.UintVarP(&deadLettersLimitFlag, "limit", "l", 0, "Set the maximum count to list")
+
+	cmd.AddCommand(deadLettersCmd)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cryptellation/ticks/configs"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/binance"
+	"github.com/cryptellation/ticks/svc/exchanges/bybit"
+	"github.com/cryptellation/ticks/svc/exchanges/coinbase"
+	"github.com/cryptellation/ticks/svc/exchanges/kraken"
+	"github.com/cryptellation/ticks/svc/exchanges/okx"
+	"github.com/cryptellation/ticks/svc/exchanges/replay"
+	"github.com/cryptellation/ticks/svc/exchanges/simulated"
+	"github.com/spf13/viper"
+	"go.temporal.io/sdk/client"
+)
+
+// exchangeFactory creates an exchange activities if it is enabled in the configuration.
+type exchangeFactory struct {
+	enabledKey string
+	create     func(temporalClient client.Client) (exchanges.Exchanges, error)
+}
+
+// exchangeFactories lists all the exchanges that can be enabled on the worker.
+var exchangeFactories = []exchangeFactory{
+	{enabledKey: configs.EnvBinanceEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return binance.New(
+			temporalClient,
+			viper.GetString(configs.EnvBinanceAPIKey),
+			viper.GetString(configs.EnvBinanceSecretKey),
+		)
+	}},
+	{enabledKey: configs.EnvBybitEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return bybit.New(temporalClient, viper.GetString(configs.EnvBybitWebsocketURL))
+	}},
+	{enabledKey: configs.EnvCoinbaseEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return coinbase.New(temporalClient, viper.GetString(configs.EnvCoinbaseWebsocketURL))
+	}},
+	{enabledKey: configs.EnvKrakenEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return kraken.New(temporalClient, viper.GetString(configs.EnvKrakenWebsocketURL))
+	}},
+	{enabledKey: configs.EnvOKXEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return okx.New(temporalClient, viper.GetString(configs.EnvOKXWebsocketURL))
+	}},
+	{enabledKey: configs.EnvSimulatedEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return simulated.New(temporalClient, simulated.Config{
+			Seed:         viper.GetUint64(configs.EnvSimulatedSeed),
+			Rate:         viper.GetDuration(configs.EnvSimulatedRate),
+			Pairs:        splitList(viper.GetString(configs.EnvSimulatedPairs)),
+			Volatility:   viper.GetFloat64(configs.EnvSimulatedVolatility),
+			InitialPrice: viper.GetFloat64(configs.EnvSimulatedInitialPrice),
+		})
+	}},
+	{enabledKey: configs.EnvReplayEnabled, create: func(temporalClient client.Client) (exchanges.Exchanges, error) {
+		return replay.New(temporalClient, replay.Config{
+			Directory: viper.GetString(configs.EnvReplayDirectory),
+			Speed:     viper.GetFloat64(configs.EnvReplaySpeed),
+		})
+	}},
+}
+
+// createExchanges creates the exchanges activities enabled in the configuration.
+func createExchanges(temporalClient client.Client) ([]exchanges.Exchanges, error) {
+	exchs := make([]exchanges.Exchanges, 0, len(exchangeFactories))
+	for _, f := range exchangeFactories {
+		if !viper.GetBool(f.enabledKey) {
+			continue
+		}
+
+		exch, err := f.create(temporalClient)
+		if err != nil {
+			return nil, err
+		}
+		exchs = append(exchs, exch)
+	}
+
+	if len(exchs) == 0 {
+		return nil, errors.New("at least one exchange must be enabled")
+	}
+
+	return exchs, nil
+}
+
+// splitList splits a comma-separated list and removes empty elements.
+func splitList(list string) []string {
+	elements := make([]string, 0)
+	for _, e := range strings.Split(list, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
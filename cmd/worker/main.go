@@ -17,6 +17,8 @@ var rootCmd = &cobra.Command{
 func main() {
 	// Set commands
 	rootCmd.AddCommand(serveCmd)
+	addDatabaseCommands(rootCmd)
+	addDeadLettersCommands(rootCmd)
 
 	// Execute command
 	if err := rootCmd.Execute(); err != nil {
@@ -11,8 +11,8 @@ import (
 	"github.com/cryptellation/ticks/api"
 	"github.com/cryptellation/ticks/configs"
 	"github.com/cryptellation/ticks/svc"
+	"github.com/cryptellation/ticks/svc/db/sql"
 	"github.com/cryptellation/ticks/svc/exchanges/aggregator"
-	"github.com/cryptellation/ticks/svc/exchanges/binance"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.temporal.io/sdk/client"
@@ -118,28 +118,33 @@ func setupWorker(ctx context.Context, eg *errgroup.Group) (temporalwk.Worker, fu
 
 // setupService creates the db, exchanges, and service and registers them to the worker.
 func setupService(ctx context.Context, w temporalwk.Worker) error {
-	// Create temporal client for binance and service
+	// Create db client
+	db, err := createDBClient(ctx)
+	if err != nil {
+		return err
+	}
+	db.Register(w)
+
+	// Create temporal client for exchanges and service
 	temporalClient, err := createTemporalClient(ctx)
 	if err != nil {
 		return err
 	}
 
-	// Create binance activities
-	binanceActivities, err := binance.New(
-		temporalClient,
-		viper.GetString(configs.EnvBinanceAPIKey),
-		viper.GetString(configs.EnvBinanceSecretKey),
-	)
+	// Create enabled exchanges activities
+	enabledExchanges, err := createExchanges(temporalClient)
 	if err != nil {
 		return err
 	}
 
 	// Create exchanges aggregator
-	exchs := aggregator.New(binanceActivities)
+	exchs := aggregator.New(enabledExchanges...)
 	exchs.Register(w)
 
 	// Create service
-	service := svc.New(temporalClient, exchs)
+	service := svc.New(temporalClient, db, exchs, svc.Options{
+		SentryMaxHistoryEvents: viper.GetInt(configs.EnvSentryMaxHistoryEvents),
+	})
 	service.Register(w)
 
 	return nil
@@ -158,3 +163,15 @@ func createTemporalClient(ctx context.Context) (client.Client, error) {
 		backoff.WithBackOff(backoff.NewExponentialBackOff()),
 		backoff.WithMaxTries(10))
 }
+
+func createDBClient(ctx context.Context) (*sql.Activities, error) {
+	// Set backoff callback
+	callback := func() (*sql.Activities, error) {
+		return sql.New(ctx, viper.GetString(configs.EnvSQLDSN))
+	}
+
+	// Retry with backoff
+	return backoff.Retry(ctx, callback,
+		backoff.WithBackOff(backoff.NewExponentialBackOff()),
+		backoff.WithMaxTries(10))
+}
@@ -8,12 +8,70 @@ const (
 		"dbname=ticks " +
 		"sslmode=disable"
 
+	// DefaultBinanceEnabled is the default value to enable Binance exchange.
+	// It only applies when the Binance API and secret keys are set.
+	DefaultBinanceEnabled = true
+
 	// DefaultBinanceAPIKey is the default Binance API key.
 	DefaultBinanceAPIKey = ""
 
 	// DefaultBinanceSecretKey is the default Binance secret key.
 	DefaultBinanceSecretKey = ""
 
+	// DefaultBybitEnabled is the default value to enable Bybit exchange.
+	DefaultBybitEnabled = false
+
+	// DefaultBybitWebsocketURL is the default Bybit spot public websocket URL.
+	DefaultBybitWebsocketURL = "wss://stream.bybit.com/v5/public/spot"
+
+	// DefaultCoinbaseEnabled is the default value to enable Coinbase exchange.
+	DefaultCoinbaseEnabled = false
+
+	// DefaultCoinbaseWebsocketURL is the default Coinbase websocket feed URL.
+	DefaultCoinbaseWebsocketURL = "wss://ws-feed.exchange.coinbase.com"
+
+	// DefaultKrakenEnabled is the default value to enable Kraken exchange.
+	DefaultKrakenEnabled = false
+
+	// DefaultKrakenWebsocketURL is the default Kraken websocket URL.
+	DefaultKrakenWebsocketURL = "wss://ws.kraken.com"
+
+	// DefaultOKXEnabled is the default value to enable OKX exchange.
+	DefaultOKXEnabled = false
+
+	// DefaultOKXWebsocketURL is the default OKX public websocket URL.
+	DefaultOKXWebsocketURL = "wss://ws.okx.com:8443/ws/v5/public"
+
+	// DefaultSimulatedEnabled is the default value to enable the simulated exchange.
+	DefaultSimulatedEnabled = false
+
+	// DefaultSimulatedSeed is the default seed of the simulated exchange.
+	DefaultSimulatedSeed = 0
+
+	// DefaultSimulatedRate is the default interval between two simulated ticks.
+	DefaultSimulatedRate = "1s"
+
+	// DefaultSimulatedPairs is the default comma-separated list of simulated pairs.
+	DefaultSimulatedPairs = "BTC-USDT,ETH-USDT"
+
+	// DefaultSimulatedVolatility is the default volatility of the simulated prices.
+	DefaultSimulatedVolatility = 0.001
+
+	// DefaultSimulatedInitialPrice is the default initial price of the simulated pairs.
+	DefaultSimulatedInitialPrice = 100.0
+
+	// DefaultReplayEnabled is the default value to enable the replay exchange.
+	DefaultReplayEnabled = false
+
+	// DefaultReplayDirectory is the default directory of the replay exchange recorded ticks.
+	DefaultReplayDirectory = ""
+
+	// DefaultReplaySpeed is the default replay speed (1 is the original pace, 0 is as fast as possible).
+	DefaultReplaySpeed = 1.0
+
+	// DefaultSentryMaxHistoryEvents is the default history size after which a sentry continues as new.
+	DefaultSentryMaxHistoryEvents = 10000
+
 	// DefaultTemporalAddress is the default Temporal address.
 	DefaultTemporalAddress = "localhost:7233"
 
@@ -5,12 +5,70 @@ import "github.com/spf13/viper"
 // EnvSQLDSN is the environment variable name for the database DSN in the config.
 const EnvSQLDSN = "SQL_DSN"
 
+// EnvBinanceEnabled is the environment variable name to enable Binance exchange in the config.
+const EnvBinanceEnabled = "BINANCE_ENABLED"
+
 // EnvBinanceAPIKey is the environment variable name for the Binance API key in the config.
 const EnvBinanceAPIKey = "BINANCE_API_KEY"
 
 // EnvBinanceSecretKey is the environment variable name for the Binance secret key in the config.
 const EnvBinanceSecretKey = "BINANCE_SECRET_KEY"
 
+// EnvBybitEnabled is the environment variable name to enable Bybit exchange in the config.
+const EnvBybitEnabled = "BYBIT_ENABLED"
+
+// EnvBybitWebsocketURL is the environment variable name for the Bybit websocket URL in the config.
+const EnvBybitWebsocketURL = "BYBIT_WEBSOCKET_URL"
+
+// EnvCoinbaseEnabled is the environment variable name to enable Coinbase exchange in the config.
+const EnvCoinbaseEnabled = "COINBASE_ENABLED"
+
+// EnvCoinbaseWebsocketURL is the environment variable name for the Coinbase websocket feed URL in the config.
+const EnvCoinbaseWebsocketURL = "COINBASE_WEBSOCKET_URL"
+
+// EnvKrakenEnabled is the environment variable name to enable Kraken exchange in the config.
+const EnvKrakenEnabled = "KRAKEN_ENABLED"
+
+// EnvKrakenWebsocketURL is the environment variable name for the Kraken websocket URL in the config.
+const EnvKrakenWebsocketURL = "KRAKEN_WEBSOCKET_URL"
+
+// EnvOKXEnabled is the environment variable name to enable OKX exchange in the config.
+const EnvOKXEnabled = "OKX_ENABLED"
+
+// EnvOKXWebsocketURL is the environment variable name for the OKX websocket URL in the config.
+const EnvOKXWebsocketURL = "OKX_WEBSOCKET_URL"
+
+// EnvSimulatedEnabled is the environment variable name to enable the simulated exchange in the config.
+const EnvSimulatedEnabled = "SIMULATED_ENABLED"
+
+// EnvSimulatedSeed is the environment variable name for the simulated exchange seed in the config.
+const EnvSimulatedSeed = "SIMULATED_SEED"
+
+// EnvSimulatedRate is the environment variable name for the simulated exchange tick rate in the config.
+const EnvSimulatedRate = "SIMULATED_RATE"
+
+// EnvSimulatedPairs is the environment variable name for the simulated exchange pairs in the config.
+const EnvSimulatedPairs = "SIMULATED_PAIRS"
+
+// EnvSimulatedVolatility is the environment variable name for the simulated exchange volatility in the config.
+const EnvSimulatedVolatility = "SIMULATED_VOLATILITY"
+
+// EnvSimulatedInitialPrice is the environment variable name for the simulated exchange initial price in the config.
+const EnvSimulatedInitialPrice = "SIMULATED_INITIAL_PRICE"
+
+// EnvReplayEnabled is the environment variable name to enable the replay exchange in the config.
+const EnvReplayEnabled = "REPLAY_ENABLED"
+
+// EnvReplayDirectory is the environment variable name for the replay exchange directory in the config.
+const EnvReplayDirectory = "REPLAY_DIRECTORY"
+
+// EnvReplaySpeed is the environment variable name for the replay exchange speed in the config.
+const EnvReplaySpeed = "REPLAY_SPEED"
+
+// EnvSentryMaxHistoryEvents is the environment variable name for the history size after which
+// a sentry workflow continues as new.
+const EnvSentryMaxHistoryEvents = "SENTRY_MAX_HISTORY_EVENTS"
+
 // EnvTemporalAddress is the environment variable name for the Temporal address in the config.
 const EnvTemporalAddress = "TEMPORAL_ADDRESS"
 
@@ -25,6 +83,32 @@ func init() {
 	viper.SetDefault(EnvSQLDSN, DefaultDBDSN)
 	viper.SetDefault(EnvBinanceAPIKey, DefaultBinanceAPIKey)
 	viper.SetDefault(EnvBinanceSecretKey, DefaultBinanceSecretKey)
+	setBinanceEnabledDefault()
+	viper.SetDefault(EnvBybitEnabled, DefaultBybitEnabled)
+	viper.SetDefault(EnvBybitWebsocketURL, DefaultBybitWebsocketURL)
+	viper.SetDefault(EnvCoinbaseEnabled, DefaultCoinbaseEnabled)
+	viper.SetDefault(EnvCoinbaseWebsocketURL, DefaultCoinbaseWebsocketURL)
+	viper.SetDefault(EnvKrakenEnabled, DefaultKrakenEnabled)
+	viper.SetDefault(EnvKrakenWebsocketURL, DefaultKrakenWebsocketURL)
+	viper.SetDefault(EnvOKXEnabled, DefaultOKXEnabled)
+	viper.SetDefault(EnvOKXWebsocketURL, DefaultOKXWebsocketURL)
+	viper.SetDefault(EnvSimulatedEnabled, DefaultSimulatedEnabled)
+	viper.SetDefault(EnvSimulatedSeed, DefaultSimulatedSeed)
+	viper.SetDefault(EnvSimulatedRate, DefaultSimulatedRate)
+	viper.SetDefault(EnvSimulatedPairs, DefaultSimulatedPairs)
+	viper.SetDefault(EnvSimulatedVolatility, DefaultSimulatedVolatility)
+	viper.SetDefault(EnvSimulatedInitialPrice, DefaultSimulatedInitialPrice)
+	viper.SetDefault(EnvReplayEnabled, DefaultReplayEnabled)
+	viper.SetDefault(EnvReplayDirectory, DefaultReplayDirectory)
+	viper.SetDefault(EnvReplaySpeed, DefaultReplaySpeed)
+	viper.SetDefault(EnvSentryMaxHistoryEvents, DefaultSentryMaxHistoryEvents)
 	viper.SetDefault(EnvTemporalAddress, DefaultTemporalAddress)
 	viper.SetDefault(EnvHealthAddress, DefaultHealthAddress)
 }
+
+// setBinanceEnabledDefault enables Binance by default only when its keys are
+// set, as it cannot be used without them.
+func setBinanceEnabledDefault() {
+	hasKeys := viper.GetString(EnvBinanceAPIKey) != "" && viper.GetString(EnvBinanceSecretKey) != ""
+	viper.SetDefault(EnvBinanceEnabled, DefaultBinanceEnabled && hasKeys)
+}
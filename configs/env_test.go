@@ -30,3 +30,21 @@ func (suite *ViperSuite) TestDBDSN() {
 	// Test the overridden value of the database DSN
 	suite.Equal("test", viper.GetString(EnvSQLDSN))
 }
+
+func (suite *ViperSuite) TestBinanceEnabledDefault() {
+	// Binance is not enabled by default without its keys
+	suite.T().Setenv(EnvBinanceAPIKey, "")
+	suite.T().Setenv(EnvBinanceSecretKey, "")
+	setBinanceEnabledDefault()
+	suite.False(viper.GetBool(EnvBinanceEnabled))
+
+	// It is enabled by default with them
+	suite.T().Setenv(EnvBinanceAPIKey, "key")
+	suite.T().Setenv(EnvBinanceSecretKey, "secret")
+	setBinanceEnabledDefault()
+	suite.True(viper.GetBool(EnvBinanceEnabled))
+
+	// Explicit configuration takes precedence
+	suite.T().Setenv(EnvBinanceEnabled, "false")
+	suite.False(viper.GetBool(EnvBinanceEnabled))
+}
@@ -0,0 +1,9 @@
+package down
+
+import "embed"
+
+// Migrations contains all the migrations to be applied to the database when
+// rolling back.
+//
+//go:embed *.sql
+var Migrations embed.FS
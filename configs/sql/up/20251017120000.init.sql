@@ -0,0 +1,17 @@
+CREATE TABLE ticks
+(
+    id BIGSERIAL NOT NULL,
+    exchange VARCHAR(100) NOT NULL,
+    pair VARCHAR(100) NOT NULL,
+    feed VARCHAR(100) NOT NULL,
+    kind VARCHAR(100) NOT NULL,
+    time TIMESTAMP NOT NULL,
+    trade_id VARCHAR(100) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    CONSTRAINT pk_ticks PRIMARY KEY (id),
+    CONSTRAINT uq_ticks_exchange_pair_feed_kind_time_trade_id_hash
+        UNIQUE (exchange, pair, feed, kind, time, trade_id, hash)
+);
+
+CREATE INDEX idx_ticks_exchange_pair_feed_time ON ticks (exchange, pair, feed, time);
@@ -0,0 +1,19 @@
+CREATE TABLE dead_letters
+(
+    id BIGSERIAL NOT NULL,
+    delivery_id VARCHAR(255) NOT NULL,
+    requester_id UUID NOT NULL,
+    exchange VARCHAR(100) NOT NULL,
+    pair VARCHAR(100) NOT NULL,
+    kind VARCHAR(100) NOT NULL,
+    target JSONB NOT NULL,
+    ticks JSONB NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    failed_at TIMESTAMP NOT NULL,
+    redelivered_at TIMESTAMP,
+    CONSTRAINT pk_dead_letters PRIMARY KEY (id),
+    CONSTRAINT uq_dead_letters_delivery_id UNIQUE (delivery_id)
+);
+
+CREATE INDEX idx_dead_letters_requester_id ON dead_letters (requester_id);
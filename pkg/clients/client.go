@@ -7,6 +7,7 @@ import (
 
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/google/uuid"
 	"github.com/iancoleman/strcase"
 	temporalclient "go.temporal.io/sdk/client"
@@ -18,8 +19,21 @@ import (
 type ListenerParams struct {
 	RequesterID uuid.UUID
 
+	// Kind is the kind of ticks to listen to (book or trade).
+	// Defaults to book ticks if empty.
+	Kind tick.Kind
+
+	// Delivery is the options of the ticks delivery to the callback.
+	Delivery api.DeliveryOptions
+
 	CallbackNamePrefix string
 	Callback           func(ctx workflow.Context, params api.ListenToTicksCallbackWorkflowParams) error
+	// BatchCallback receives the ticks by batches, instead of Callback.
+	// It requires the batching to be enabled in the delivery options.
+	BatchCallback func(ctx workflow.Context, params api.ListenToTicksBatchCallbackWorkflowParams) error
+	// EndedCallback is called when the service ends the subscription, for
+	// example after too many failed deliveries. It is optional.
+	EndedCallback func(ctx workflow.Context, params api.SubscriptionEndedCallbackWorkflowParams) error
 
 	Worker    worker.Worker
 	TaskQueue string
@@ -40,6 +54,28 @@ type Client interface {
 		exchange string,
 		pair string,
 	) error
+	// GetTicks gets the recorded ticks from the given exchange and pair.
+	GetTicks(
+		ctx context.Context,
+		params api.GetTicksWorkflowParams,
+	) (api.GetTicksWorkflowResults, error)
+	// LastTick gets the last known book tick from the given exchange and pair.
+	LastTick(ctx context.Context, exchange, pair string) (tick.Tick, error)
+	// ListSubscriptions lists the running sentries and their subscriptions.
+	ListSubscriptions(
+		ctx context.Context,
+		params api.ListSubscriptionsWorkflowParams,
+	) (api.ListSubscriptionsWorkflowResults, error)
+	// ListDeadLetters lists the deliveries of ticks that have failed.
+	ListDeadLetters(
+		ctx context.Context,
+		params api.ListDeadLettersWorkflowParams,
+	) (api.ListDeadLettersWorkflowResults, error)
+	// RedeliverDeadLetters delivers again the ticks whose delivery has failed.
+	RedeliverDeadLetters(
+		ctx context.Context,
+		params api.RedeliverDeadLettersWorkflowParams,
+	) (api.RedeliverDeadLettersWorkflowResults, error)
 	// Info calls the service info.
 	Info(ctx context.Context) (api.ServiceInfoResults, error)
 	// TemporalClient returns the underlying temporal client.
@@ -88,6 +124,14 @@ func (c client) ListenToTicks(
 		return fmt.Errorf("RequesterID must be provided")
 	}
 
+	// Check that the callback matches the delivery mode
+	if (listener.Callback == nil) == (listener.BatchCallback == nil) {
+		return fmt.Errorf("exactly one of Callback or BatchCallback must be provided")
+	}
+	if (listener.BatchCallback != nil) != listener.Delivery.Batch.Enabled() {
+		return fmt.Errorf("BatchCallback must be provided if and only if batching is enabled")
+	}
+
 	// Generate a callback name
 	var callbackName string
 	if listener.CallbackNamePrefix != "" {
@@ -97,20 +141,37 @@ func (c client) ListenToTicks(
 	}
 
 	// Register the workflow with the provided worker
-	listener.Worker.RegisterWorkflowWithOptions(listener.Callback, workflow.RegisterOptions{
+	var callback any = listener.Callback
+	if listener.BatchCallback != nil {
+		callback = listener.BatchCallback
+	}
+	listener.Worker.RegisterWorkflowWithOptions(callback, workflow.RegisterOptions{
 		Name: callbackName,
 	})
 
+	// Register the subscription ended callback, if any
+	if listener.EndedCallback != nil {
+		listener.Delivery.EndedCallback = runtime.CallbackWorkflow{
+			Name:          fmt.Sprintf("%s-Ended", callbackName),
+			TaskQueueName: listener.TaskQueue,
+		}
+		listener.Worker.RegisterWorkflowWithOptions(listener.EndedCallback, workflow.RegisterOptions{
+			Name: listener.Delivery.EndedCallback.Name,
+		})
+	}
+
 	// Listen to ticks
 	_, err := c.registerForTicks(ctx,
 		api.RegisterForTicksListeningWorkflowParams{
 			RequesterID: listener.RequesterID,
 			Exchange:    exchange,
 			Pair:        pair,
+			Kind:        listener.Kind,
 			Callback: runtime.CallbackWorkflow{
 				Name:          callbackName,
 				TaskQueueName: listener.TaskQueue,
 			},
+			Delivery: listener.Delivery,
 		})
 	if err != nil {
 		return err
@@ -189,6 +250,158 @@ func (c client) StopListeningToTicks(
 	return nil
 }
 
+// GetTicks gets the recorded ticks from the given exchange and pair.
+func (c client) GetTicks(
+	ctx context.Context,
+	params api.GetTicksWorkflowParams,
+) (res api.GetTicksWorkflowResults, err error) {
+	// Generate a unique ID for the workflow
+	id := fmt.Sprintf(
+		"GetTicks%s%s-%s-%s",
+		strcase.ToCamel(params.Exchange),
+		strings.ReplaceAll(params.Pair, "-", ""),
+		c.userAgent,
+		uuid.New().String(),
+	)
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx,
+		temporalclient.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.GetTicksWorkflowName,
+		params)
+	if err != nil {
+		return api.GetTicksWorkflowResults{}, err
+	}
+
+	// Get result and return
+	err = exec.Get(ctx, &res)
+	return res, err
+}
+
+// LastTick gets the last known book tick from the given exchange and pair.
+func (c client) LastTick(ctx context.Context, exchange, pair string) (tick.Tick, error) {
+	// Generate a unique ID for the workflow
+	id := fmt.Sprintf(
+		"GetLastTick%s%s-%s-%s",
+		strcase.ToCamel(exchange),
+		strings.ReplaceAll(pair, "-", ""),
+		c.userAgent,
+		uuid.New().String(),
+	)
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx,
+		temporalclient.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.GetLastTickWorkflowName,
+		api.GetLastTickWorkflowParams{
+			Exchange: exchange,
+			Pair:     pair,
+		})
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	// Get result and return
+	var res api.GetLastTickWorkflowResults
+	if err := exec.Get(ctx, &res); err != nil {
+		return tick.Tick{}, err
+	}
+	return res.Tick, nil
+}
+
+// ListSubscriptions lists the running sentries and their subscriptions.
+func (c client) ListSubscriptions(
+	ctx context.Context,
+	params api.ListSubscriptionsWorkflowParams,
+) (res api.ListSubscriptionsWorkflowResults, err error) {
+	// Generate a unique ID for the workflow
+	id := fmt.Sprintf(
+		"ListSubscriptions-%s-%s",
+		c.userAgent,
+		uuid.New().String(),
+	)
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx,
+		temporalclient.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.ListSubscriptionsWorkflowName,
+		params)
+	if err != nil {
+		return api.ListSubscriptionsWorkflowResults{}, err
+	}
+
+	// Get result and return
+	err = exec.Get(ctx, &res)
+	return res, err
+}
+
+// ListDeadLetters lists the deliveries of ticks that have failed.
+func (c client) ListDeadLetters(
+	ctx context.Context,
+	params api.ListDeadLettersWorkflowParams,
+) (res api.ListDeadLettersWorkflowResults, err error) {
+	// Generate a unique ID for the workflow
+	id := fmt.Sprintf(
+		"ListDeadLetters-%s-%s",
+		c.userAgent,
+		uuid.New().String(),
+	)
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx,
+		temporalclient.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.ListDeadLettersWorkflowName,
+		params)
+	if err != nil {
+		return api.ListDeadLettersWorkflowResults{}, err
+	}
+
+	// Get result and return
+	err = exec.Get(ctx, &res)
+	return res, err
+}
+
+// RedeliverDeadLetters delivers again the ticks whose delivery has failed.
+func (c client) RedeliverDeadLetters(
+	ctx context.Context,
+	params api.RedeliverDeadLettersWorkflowParams,
+) (res api.RedeliverDeadLettersWorkflowResults, err error) {
+	// Generate a unique ID for the workflow
+	id := fmt.Sprintf(
+		"RedeliverDeadLetters-%s-%s",
+		c.userAgent,
+		uuid.New().String(),
+	)
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx,
+		temporalclient.StartWorkflowOptions{
+			ID:        id,
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.RedeliverDeadLettersWorkflowName,
+		params)
+	if err != nil {
+		return api.RedeliverDeadLettersWorkflowResults{}, err
+	}
+
+	// Get result and return
+	err = exec.Get(ctx, &res)
+	return res, err
+}
+
 // Info calls the service info.
 func (c client) Info(ctx context.Context) (res api.ServiceInfoResults, err error) {
 	// Generate a unique ID for the workflow
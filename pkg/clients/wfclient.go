@@ -13,11 +13,32 @@ type WfClient interface {
 		params api.RegisterForTicksListeningWorkflowParams,
 	) (api.RegisterForTicksListeningWorkflowResults, error)
 
+	// ListenToTicksWithSignal listens to ticks from the given exchange and pair,
+	// delivered as signals to the current workflow, unless another workflow is
+	// set as the signal target. It returns the channel receiving the ticks, as
+	// api.ListenToTicksCallbackWorkflowParams (or
+	// api.ListenToTicksBatchCallbackWorkflowParams if batching is enabled).
+	ListenToTicksWithSignal(
+		ctx workflow.Context,
+		params api.RegisterForTicksListeningWorkflowParams,
+	) (workflow.ReceiveChannel, error)
+
+	// TicksSignalChannel returns the channel receiving the ticks delivered as
+	// signals to the current workflow, for example after it continued as new.
+	// The signal name defaults to api.DefaultTicksSignalName if empty.
+	TicksSignalChannel(ctx workflow.Context, signalName string) workflow.ReceiveChannel
+
 	// StopListeningToTicks unregisters a callback workflow from ticks for a given exchange and pair.
 	StopListeningToTicks(
 		ctx workflow.Context,
 		params api.UnregisterFromTicksListeningWorkflowParams,
 	) (api.UnregisterFromTicksListeningWorkflowResults, error)
+
+	// GetTicks gets the recorded ticks from the given exchange and pair.
+	GetTicks(
+		ctx workflow.Context,
+		params api.GetTicksWorkflowParams,
+	) (api.GetTicksWorkflowResults, error)
 }
 
 type wfClient struct{}
@@ -50,6 +71,35 @@ func (c wfClient) ListenToTicks(
 	return res, nil
 }
 
+// ListenToTicksWithSignal listens to ticks from the given exchange and pair,
+// delivered as signals to the current workflow.
+func (c wfClient) ListenToTicksWithSignal(
+	ctx workflow.Context,
+	params api.RegisterForTicksListeningWorkflowParams,
+) (workflow.ReceiveChannel, error) {
+	// Target the current workflow by default
+	if !params.Signal.IsSet() {
+		params.Signal.WorkflowID = workflow.GetInfo(ctx).WorkflowExecution.ID
+	}
+	params.Signal = params.Signal.WithDefaults()
+
+	// Listen to ticks
+	if _, err := c.ListenToTicks(ctx, params); err != nil {
+		return nil, err
+	}
+
+	return c.TicksSignalChannel(ctx, params.Signal.SignalName), nil
+}
+
+// TicksSignalChannel returns the channel receiving the ticks delivered as
+// signals to the current workflow.
+func (c wfClient) TicksSignalChannel(ctx workflow.Context, signalName string) workflow.ReceiveChannel {
+	if signalName == "" {
+		signalName = api.DefaultTicksSignalName
+	}
+	return workflow.GetSignalChannel(ctx, signalName)
+}
+
 // StopListeningToTicks unregisters a callback workflow from ticks for a given exchange and pair.
 func (c wfClient) StopListeningToTicks(
 	ctx workflow.Context,
@@ -70,3 +120,24 @@ func (c wfClient) StopListeningToTicks(
 
 	return res, nil
 }
+
+// GetTicks gets the recorded ticks from the given exchange and pair.
+func (c wfClient) GetTicks(
+	ctx workflow.Context,
+	params api.GetTicksWorkflowParams,
+) (api.GetTicksWorkflowResults, error) {
+	// Set options
+	childWorkflowOptions := workflow.ChildWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+	ctx = workflow.WithChildOptions(ctx, childWorkflowOptions)
+
+	// Execute child workflow
+	var res api.GetTicksWorkflowResults
+	err := workflow.ExecuteChildWorkflow(ctx, api.GetTicksWorkflowName, params).Get(ctx, &res)
+	if err != nil {
+		return api.GetTicksWorkflowResults{}, err
+	}
+
+	return res, nil
+}
@@ -7,14 +7,33 @@ import (
 	"time"
 
 	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/shopspring/decimal"
 )
 
 // Tick is the struct that will handle the ticks.
 type Tick struct {
 	Time     time.Time `json:"time"`
+	Kind     Kind      `json:"kind"`
 	Pair     string    `json:"pair"`
 	Price    float64   `json:"price"`
+	Bid      float64   `json:"bid"`
+	Ask      float64   `json:"ask"`
+	BidQty   float64   `json:"bid_qty"`
+	AskQty   float64   `json:"ask_qty"`
+	Qty      float64   `json:"qty"`
+	Side     Side      `json:"side"`
 	Exchange string    `json:"exchange"`
+
+	// TradeID is the ID given by the exchange to the trade of trade ticks, if
+	// any. It tells apart the trades with the same time, price, qty and side.
+	TradeID string `json:"trade_id"`
+
+	// GapStart is the start of the interruption for gap ticks.
+	GapStart time.Time `json:"gap_start"`
+
+	// Exact is the lossless representation of the prices and quantities.
+	// Float fields are kept for compatibility and may lose precision.
+	Exact Exact `json:"exact"`
 }
 
 // FromCandlestick creates a Tick from a candlestick.
@@ -24,12 +43,108 @@ func FromCandlestick(
 	t time.Time,
 	cs candlestick.Candlestick,
 ) Tick {
+	price := cs.Price(currentPriceType)
+	exactPrice := decimal.NewFromFloat(price)
 	return Tick{
 		Time:     t,
 		Pair:     pair,
-		Price:    cs.Price(currentPriceType),
+		Price:    price,
+		Bid:      price,
+		Ask:      price,
+		Exchange: exchange,
+		Exact: Exact{
+			Price: exactPrice,
+			Bid:   exactPrice,
+			Ask:   exactPrice,
+		},
+	}
+}
+
+// FromBook creates a Tick from the best bid and ask of an order book.
+// The price is set to the mid price between bid and ask.
+func FromBook(
+	exchange, pair string,
+	t time.Time,
+	bid, bidQty, ask, askQty float64,
+) Tick {
+	return FromExactBook(exchange, pair, t,
+		decimal.NewFromFloat(bid), decimal.NewFromFloat(bidQty),
+		decimal.NewFromFloat(ask), decimal.NewFromFloat(askQty))
+}
+
+// FromExactBook creates a Tick from the exact best bid and ask of an order book.
+// The price is set to the mid price between bid and ask.
+func FromExactBook(
+	exchange, pair string,
+	t time.Time,
+	bid, bidQty, ask, askQty decimal.Decimal,
+) Tick {
+	exact := Exact{
+		Price:  midPrice(bid, ask),
+		Bid:    bid,
+		Ask:    ask,
+		BidQty: bidQty,
+		AskQty: askQty,
+	}
+
+	tick := Tick{
+		Time:     t,
+		Kind:     KindBook,
+		Pair:     pair,
+		Exchange: exchange,
+		Exact:    exact,
+	}
+	tick.Price, tick.Bid, tick.Ask, tick.BidQty, tick.AskQty, tick.Qty = exact.Float()
+
+	return tick
+}
+
+// FromExactTrade creates a Tick from an executed trade.
+// The price is set to the trade price.
+func FromExactTrade(
+	exchange, pair string,
+	t time.Time,
+	price, qty decimal.Decimal,
+	side Side,
+) Tick {
+	exact := Exact{
+		Price: price,
+		Qty:   qty,
+	}
+
+	tick := Tick{
+		Time:     t,
+		Kind:     KindTrade,
+		Pair:     pair,
+		Side:     side,
 		Exchange: exchange,
+		Exact:    exact,
 	}
+	tick.Price, tick.Bid, tick.Ask, tick.BidQty, tick.AskQty, tick.Qty = exact.Float()
+
+	return tick
+}
+
+// FromGap creates a gap marker Tick, signaling that ticks may be missing
+// between start and end because the exchange feed has been interrupted.
+func FromGap(exchange, pair string, start, end time.Time) Tick {
+	return Tick{
+		Time:     end,
+		Kind:     KindGap,
+		Pair:     pair,
+		Exchange: exchange,
+		GapStart: start,
+	}
+}
+
+// IsGap returns true if the tick is a gap marker.
+func (t Tick) IsGap() bool {
+	return t.Kind == KindGap
+}
+
+// Spread returns the difference between the ask and the bid.
+func (t Tick) Spread() float64 {
+	return t.Ask - t.Bid
 }
 
 // MarshalBinary marshals a Tick into a byte slice.
@@ -0,0 +1,48 @@
+package tick
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Exact is the lossless representation of the prices and quantities of a tick.
+type Exact struct {
+	Price  decimal.Decimal `json:"price"`
+	Bid    decimal.Decimal `json:"bid"`
+	Ask    decimal.Decimal `json:"ask"`
+	BidQty decimal.Decimal `json:"bid_qty"`
+	AskQty decimal.Decimal `json:"ask_qty"`
+	Qty    decimal.Decimal `json:"qty"`
+}
+
+// Spread returns the exact difference between the ask and the bid.
+func (e Exact) Spread() decimal.Decimal {
+	return e.Ask.Sub(e.Bid)
+}
+
+// Equal returns true if both exact representations hold the same values.
+func (e Exact) Equal(other Exact) bool {
+	return e.Price.Equal(other.Price) &&
+		e.Bid.Equal(other.Bid) &&
+		e.Ask.Equal(other.Ask) &&
+		e.BidQty.Equal(other.BidQty) &&
+		e.AskQty.Equal(other.AskQty) &&
+		e.Qty.Equal(other.Qty)
+}
+
+// Float returns the float representation of the exact values, as used in the
+// compatibility fields of the tick: price, bid, ask, bid quantity, ask quantity
+// and trade quantity.
+func (e Exact) Float() (price, bid, ask, bidQty, askQty, qty float64) {
+	return e.Price.InexactFloat64(),
+		e.Bid.InexactFloat64(),
+		e.Ask.InexactFloat64(),
+		e.BidQty.InexactFloat64(),
+		e.AskQty.InexactFloat64(),
+		e.Qty.InexactFloat64()
+}
+
+// midPrice returns the exact mid price between bid and ask.
+// It multiplies by 0.5 instead of dividing by 2 to avoid rounding.
+func midPrice(bid, ask decimal.Decimal) decimal.Decimal {
+	return bid.Add(ask).Mul(decimal.New(5, -1))
+}
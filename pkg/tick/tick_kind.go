@@ -0,0 +1,59 @@
+package tick
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKind is the error when the tick kind is unknown.
+var ErrUnknownKind = errors.New("unknown tick kind")
+
+// Kind is the kind of market event that generated a tick.
+type Kind string
+
+const (
+	// KindBook is a tick generated by a change of the best bid/ask of the book.
+	KindBook Kind = "book"
+	// KindTrade is a tick generated by an executed trade.
+	KindTrade Kind = "trade"
+	// KindGap is a marker tick sent when the exchange feed has been interrupted:
+	// ticks may be missing between its GapStart and its Time.
+	KindGap Kind = "gap"
+)
+
+// Kinds is the list of the tick kinds that can be listened to.
+// Gap ticks are sent on every kind of feed and cannot be listened to alone.
+var Kinds = []Kind{KindBook, KindTrade}
+
+// OrDefault returns the kind or the default kind (book) if it is empty.
+func (k Kind) OrDefault() Kind {
+	if k == "" {
+		return KindBook
+	}
+	return k
+}
+
+// Validate returns an error if the kind is not empty and not a known kind.
+func (k Kind) Validate() error {
+	if k == "" {
+		return nil
+	}
+
+	for _, kind := range Kinds {
+		if k == kind {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownKind, k)
+}
+
+// Side is the side of the aggressor of a trade.
+type Side string
+
+const (
+	// SideBuy is when the buyer was the aggressor (taker) of the trade.
+	SideBuy Side = "buy"
+	// SideSell is when the seller was the aggressor (taker) of the trade.
+	SideSell Side = "sell"
+)
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -24,6 +25,10 @@ func (suite *TickSuite) TestMarshalingJSON() {
 		Time:     time.Unix(60, 0).UTC(),
 		Pair:     "BTC-USDC",
 		Price:    1.01,
+		Bid:      1.00,
+		Ask:      1.02,
+		BidQty:   3,
+		AskQty:   4,
 		Exchange: "exchange",
 	}
 
@@ -32,5 +37,64 @@ func (suite *TickSuite) TestMarshalingJSON() {
 
 	tick2 := Tick{}
 	suite.Require().NoError(json.Unmarshal(b, &tick2))
+	suite.Require().True(tick.Exact.Equal(tick2.Exact))
+	tick.Exact, tick2.Exact = Exact{}, Exact{}
 	suite.Require().Equal(tick, tick2)
 }
+
+func (suite *TickSuite) TestMarshalingBinaryExact() {
+	tick := FromExactBook("exchange", "SHIB-USDT", time.Unix(60, 0).UTC(),
+		decimal.RequireFromString("0.00000123"), decimal.RequireFromString("1000000"),
+		decimal.RequireFromString("0.00000124"), decimal.RequireFromString("2000000"))
+
+	b, err := tick.MarshalBinary()
+	suite.Require().NoError(err)
+
+	tick2 := Tick{}
+	suite.Require().NoError(tick2.UnmarshalBinary(b))
+	suite.Require().Equal("0.00000123", tick2.Exact.Bid.String())
+	suite.Require().Equal("0.00000124", tick2.Exact.Ask.String())
+	suite.Require().Equal("0.000001235", tick2.Exact.Price.String())
+	suite.Require().Equal("0.00000001", tick2.Exact.Spread().String())
+	suite.Require().True(tick.Exact.Equal(tick2.Exact))
+	suite.Require().Equal(tick.Price, tick2.Price)
+}
+
+func (suite *TickSuite) TestFromBook() {
+	t := FromBook("exchange", "BTC-USDC", time.Unix(60, 0).UTC(), 99, 1.5, 101, 2.5)
+
+	suite.Require().Equal(100.0, t.Price)
+	suite.Require().Equal(99.0, t.Bid)
+	suite.Require().Equal(101.0, t.Ask)
+	suite.Require().Equal(1.5, t.BidQty)
+	suite.Require().Equal(2.5, t.AskQty)
+	suite.Require().Equal(2.0, t.Spread())
+}
+
+func (suite *TickSuite) TestFromExactTrade() {
+	t := FromExactTrade("exchange", "BTC-USDC", time.Unix(60, 0).UTC(),
+		decimal.RequireFromString("100.5"), decimal.RequireFromString("0.25"), SideSell)
+
+	suite.Require().Equal(KindTrade, t.Kind)
+	suite.Require().Equal(100.5, t.Price)
+	suite.Require().Equal(0.25, t.Qty)
+	suite.Require().Equal(SideSell, t.Side)
+}
+
+func (suite *TickSuite) TestKindValidate() {
+	suite.Require().NoError(Kind("").Validate())
+	suite.Require().NoError(KindBook.Validate())
+	suite.Require().NoError(KindTrade.Validate())
+	suite.Require().ErrorIs(Kind("unknown").Validate(), ErrUnknownKind)
+	suite.Require().Equal(KindBook, Kind("").OrDefault())
+}
+
+func (suite *TickSuite) TestFromGap() {
+	start, end := time.Unix(60, 0).UTC(), time.Unix(120, 0).UTC()
+	t := FromGap("exchange", "BTC-USDC", start, end)
+
+	suite.Require().True(t.IsGap())
+	suite.Require().Equal(start, t.GapStart)
+	suite.Require().Equal(end, t.Time)
+	suite.Require().ErrorIs(KindGap.Validate(), ErrUnknownKind)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a tick in the database, used to paginate reads.
+type Cursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// String encodes the cursor into an opaque string.
+func (c Cursor) String() string {
+	raw := fmt.Sprintf("%d:%d", c.Time.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a cursor from its opaque string.
+func ParseCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	nanoStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, s)
+	}
+
+	nano, err := strconv.ParseInt(nanoStr, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return Cursor{
+		Time: time.Unix(0, nano).UTC(),
+		ID:   id,
+	}, nil
+}
@@ -0,0 +1,37 @@
+//go:build unit
+// +build unit
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCursorSuite(t *testing.T) {
+	suite.Run(t, new(CursorSuite))
+}
+
+type CursorSuite struct {
+	suite.Suite
+}
+
+func (suite *CursorSuite) TestRoundTrip() {
+	c := Cursor{
+		Time: time.Date(2024, 1, 1, 0, 0, 1, 123456789, time.UTC),
+		ID:   42,
+	}
+
+	parsed, err := ParseCursor(c.String())
+	suite.Require().NoError(err)
+	suite.Require().Equal(c, parsed)
+}
+
+func (suite *CursorSuite) TestInvalid() {
+	for _, s := range []string{"%%%", "bm9wZQ", "YTpi"} {
+		_, err := ParseCursor(s)
+		suite.Require().ErrorIs(err, ErrInvalidCursor, s)
+	}
+}
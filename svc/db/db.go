@@ -0,0 +1,181 @@
+// Generate code for mock
+//go:generate go run go.uber.org/mock/mockgen@v0.2.0 -source=db.go -destination=db.mock.gen.go -package db
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// CreateTicksActivityName is the name of the CreateTicks activity.
+const CreateTicksActivityName = "CreateTicksActivity"
+
+type (
+	// CreateTicksActivityParams is the parameters for the CreateTicks activity.
+	CreateTicksActivityParams struct {
+		Ticks []tick.Tick
+		// Feed is the kind of the feed the gap ticks come from (book by default).
+		// The other ticks come from the feed of their kind.
+		Feed tick.Kind
+	}
+
+	// CreateTicksActivityResults is the result for the CreateTicks activity.
+	CreateTicksActivityResults struct{}
+)
+
+// ReadTicksActivityName is the name of the ReadTicks activity.
+const ReadTicksActivityName = "ReadTicksActivity"
+
+type (
+	// ReadTicksActivityParams is the parameters for the ReadTicks activity.
+	ReadTicksActivityParams struct {
+		Exchange string
+		Pair     string
+		// Kind is the kind of ticks to read (book by default).
+		// Gap ticks of the feed of this kind are always included.
+		Kind  tick.Kind
+		Start time.Time
+		End   time.Time
+		Limit uint
+		// After is the position after which the ticks are read, if set.
+		After *Cursor
+	}
+
+	// ReadTicksActivityResults is the result for the ReadTicks activity.
+	ReadTicksActivityResults struct {
+		Ticks []tick.Tick
+		// Last is the position of the last read tick, if any.
+		Last *Cursor
+	}
+)
+
+// ReadLastTickActivityName is the name of the ReadLastTick activity.
+const ReadLastTickActivityName = "ReadLastTickActivity"
+
+type (
+	// ReadLastTickActivityParams is the parameters for the ReadLastTick activity.
+	ReadLastTickActivityParams struct {
+		Exchange string
+		Pair     string
+		// Kind is the kind of tick to read (book by default).
+		// Gap ticks are never returned.
+		Kind tick.Kind
+	}
+
+	// ReadLastTickActivityResults is the result for the ReadLastTick activity.
+	ReadLastTickActivityResults struct {
+		// Tick is the last recorded tick, nil if there is none.
+		Tick *tick.Tick
+	}
+)
+
+// CreateDeadLetterActivityName is the name of the CreateDeadLetter activity.
+const CreateDeadLetterActivityName = "CreateDeadLetterActivity"
+
+type (
+	// CreateDeadLetterActivityParams is the parameters for the CreateDeadLetter activity.
+	CreateDeadLetterActivityParams struct {
+		// DeadLetter is the failed delivery to record, its ID is ignored.
+		DeadLetter api.DeadLetter
+	}
+
+	// CreateDeadLetterActivityResults is the result for the CreateDeadLetter activity.
+	CreateDeadLetterActivityResults struct {
+		// ID is the ID of the dead letter, which is the existing one if the
+		// delivery has already been recorded.
+		ID int64
+	}
+)
+
+// ReadDeadLettersActivityName is the name of the ReadDeadLetters activity.
+const ReadDeadLettersActivityName = "ReadDeadLettersActivity"
+
+type (
+	// ReadDeadLettersActivityParams is the parameters for the ReadDeadLetters activity.
+	// The filters are ignored if empty.
+	ReadDeadLettersActivityParams struct {
+		IDs                []int64
+		RequesterID        uuid.UUID
+		Exchange           string
+		Pair               string
+		IncludeRedelivered bool
+		AfterID            int64
+		Limit              uint
+	}
+
+	// ReadDeadLettersActivityResults is the result for the ReadDeadLetters activity.
+	ReadDeadLettersActivityResults struct {
+		// DeadLetters are ordered by ID.
+		DeadLetters []api.DeadLetter
+	}
+)
+
+// UpdateDeadLetterActivityName is the name of the UpdateDeadLetter activity.
+const UpdateDeadLetterActivityName = "UpdateDeadLetterActivity"
+
+type (
+	// UpdateDeadLetterActivityParams is the parameters for the UpdateDeadLetter activity.
+	UpdateDeadLetterActivityParams struct {
+		ID            int64
+		Error         string
+		Attempts      int
+		RedeliveredAt *time.Time
+	}
+
+	// UpdateDeadLetterActivityResults is the result for the UpdateDeadLetter activity.
+	UpdateDeadLetterActivityResults struct{}
+)
+
+// DB is the interface that defines the ticks activities.
+type DB interface {
+	Register(w worker.Worker)
+
+	CreateTicksActivity(
+		ctx context.Context,
+		params CreateTicksActivityParams,
+	) (CreateTicksActivityResults, error)
+
+	ReadTicksActivity(
+		ctx context.Context,
+		params ReadTicksActivityParams,
+	) (ReadTicksActivityResults, error)
+
+	ReadLastTickActivity(
+		ctx context.Context,
+		params ReadLastTickActivityParams,
+	) (ReadLastTickActivityResults, error)
+
+	CreateDeadLetterActivity(
+		ctx context.Context,
+		params CreateDeadLetterActivityParams,
+	) (CreateDeadLetterActivityResults, error)
+
+	ReadDeadLettersActivity(
+		ctx context.Context,
+		params ReadDeadLettersActivityParams,
+	) (ReadDeadLettersActivityResults, error)
+
+	UpdateDeadLetterActivity(
+		ctx context.Context,
+		params UpdateDeadLetterActivityParams,
+	) (UpdateDeadLetterActivityResults, error)
+}
+
+// DefaultActivityOptions returns the default database activities options.
+func DefaultActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		RetryPolicy: &temporal.RetryPolicy{
+			NonRetryableErrorTypes: []string{},
+		},
+		StartToCloseTimeout:    10 * time.Second,
+		ScheduleToCloseTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/db/sql/entities"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // PostGres driver
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+var _ db.DB = (*Activities)(nil)
+
+// Activities is a struct that contains all the methods to interact with the
+// ticks table in the database.
+type Activities struct {
+	db *sqlx.DB
+}
+
+// New creates a new activities.
+func New(ctx context.Context, dsn string) (*Activities, error) {
+	// Create database access
+	db, err := sqlx.ConnectContext(ctx, "postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a structure
+	a := &Activities{
+		db: db,
+	}
+
+	return a, nil
+}
+
+// Register registers the activities.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.CreateTicksActivity,
+		activity.RegisterOptions{Name: db.CreateTicksActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.ReadTicksActivity,
+		activity.RegisterOptions{Name: db.ReadTicksActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.ReadLastTickActivity,
+		activity.RegisterOptions{Name: db.ReadLastTickActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.CreateDeadLetterActivity,
+		activity.RegisterOptions{Name: db.CreateDeadLetterActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.ReadDeadLettersActivity,
+		activity.RegisterOptions{Name: db.ReadDeadLettersActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.UpdateDeadLetterActivity,
+		activity.RegisterOptions{Name: db.UpdateDeadLetterActivityName},
+	)
+}
+
+// Reset will reset the database.
+func (a *Activities) Reset(ctx context.Context) error {
+	_, err := a.db.ExecContext(ctx, "DELETE FROM ticks")
+	if err != nil {
+		return fmt.Errorf("deleting ticks rows: %w", err)
+	}
+
+	_, err = a.db.ExecContext(ctx, "DELETE FROM dead_letters")
+	if err != nil {
+		return fmt.Errorf("deleting dead letters rows: %w", err)
+	}
+
+	return nil
+}
+
+// CreateTicksActivity creates the ticks.
+func (a *Activities) CreateTicksActivity(
+	ctx context.Context,
+	params db.CreateTicksActivityParams,
+) (db.CreateTicksActivityResults, error) {
+	if len(params.Ticks) == 0 {
+		return db.CreateTicksActivityResults{}, nil
+	}
+
+	// Convert the list of ticks from the model to the entity
+	listTE, err := entities.FromModelListToEntityList(params.Ticks, params.Feed)
+	if err != nil {
+		return db.CreateTicksActivityResults{}, err
+	}
+
+	// Bulk insert the ticks, skipping the ones already inserted (i.e. by a retry)
+	_, err = a.db.NamedExecContext(
+		ctx,
+		`INSERT INTO ticks (exchange, pair, feed, kind, time, trade_id, hash, data)
+		VALUES (:exchange, :pair, :feed, :kind, :time, :trade_id, :hash, :data)
+		ON CONFLICT (exchange, pair, feed, kind, time, trade_id, hash) DO NOTHING`,
+		entities.FromEntitiesToMap(listTE),
+	)
+	if err != nil {
+		return db.CreateTicksActivityResults{}, fmt.Errorf("bulk inserting ticks: %w", err)
+	}
+
+	return db.CreateTicksActivityResults{}, nil
+}
+
+// ReadTicksActivity reads the ticks.
+func (a *Activities) ReadTicksActivity(
+	ctx context.Context,
+	params db.ReadTicksActivityParams,
+) (db.ReadTicksActivityResults, error) {
+	// Set artificial limit if there is none
+	if params.Limit == 0 {
+		params.Limit = math.MaxInt32
+	}
+
+	// Build the query, with the ticks and gaps of the feed of the kind
+	query := `SELECT id, exchange, pair, feed, kind, time, trade_id, data
+		FROM ticks
+		WHERE exchange = $1 AND pair = $2 AND feed = $3 AND time >= $4 AND time <= $5`
+	args := []interface{}{
+		params.Exchange,
+		params.Pair,
+		params.Kind.OrDefault(),
+		params.Start.UTC(),
+		params.End.UTC(),
+	}
+	if params.After != nil {
+		query += ` AND (time, id) > ($6, $7)`
+		args = append(args, params.After.Time.UTC(), params.After.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY time ASC, id ASC LIMIT $%d", len(args)+1)
+	args = append(args, params.Limit)
+
+	// Query the ticks
+	rows, err := a.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return db.ReadTicksActivityResults{}, fmt.Errorf("querying ticks: %w", err)
+	}
+	defer rows.Close()
+
+	// Loop through the results
+	ticks := make([]tick.Tick, 0)
+	var last *db.Cursor
+	for rows.Next() {
+		te := entities.Tick{}
+		if err := rows.StructScan(&te); err != nil {
+			return db.ReadTicksActivityResults{}, fmt.Errorf("scanning tick: %w", err)
+		}
+
+		t, err := te.ToModel()
+		if err != nil {
+			return db.ReadTicksActivityResults{}, fmt.Errorf("from tick entity to model: %w", err)
+		}
+		ticks = append(ticks, t)
+		last = &db.Cursor{Time: te.Time.UTC(), ID: te.ID}
+	}
+
+	return db.ReadTicksActivityResults{
+		Ticks: ticks,
+		Last:  last,
+	}, nil
+}
+
+// ReadLastTickActivity reads the last recorded tick.
+func (a *Activities) ReadLastTickActivity(
+	ctx context.Context,
+	params db.ReadLastTickActivityParams,
+) (db.ReadLastTickActivityResults, error) {
+	te := entities.Tick{}
+	err := a.db.GetContext(ctx, &te,
+		`SELECT id, exchange, pair, feed, kind, time, trade_id, data
+		FROM ticks
+		WHERE exchange = $1 AND pair = $2 AND feed = $3 AND kind = $3
+		ORDER BY time DESC, id DESC LIMIT 1`,
+		params.Exchange,
+		params.Pair,
+		params.Kind.OrDefault())
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ReadLastTickActivityResults{}, nil
+	} else if err != nil {
+		return db.ReadLastTickActivityResults{}, fmt.Errorf("querying last tick: %w", err)
+	}
+
+	t, err := te.ToModel()
+	if err != nil {
+		return db.ReadLastTickActivityResults{}, fmt.Errorf("from tick entity to model: %w", err)
+	}
+
+	return db.ReadLastTickActivityResults{
+		Tick: &t,
+	}, nil
+}
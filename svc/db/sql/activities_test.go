@@ -0,0 +1,53 @@
+//go:build integration
+// +build integration
+
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/configs"
+	"github.com/cryptellation/ticks/configs/sql/down"
+	"github.com/cryptellation/ticks/configs/sql/up"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/db/sql/migrator"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTicksSuite(t *testing.T) {
+	suite.Run(t, new(TicksSuite))
+}
+
+type TicksSuite struct {
+	db.TicksSuite
+}
+
+func (suite *TicksSuite) SetupSuite() {
+	act, err := createTestDBClient(context.Background())
+	suite.Require().NoError(err)
+
+	mig, err := migrator.NewMigrator(context.Background(), act.db, up.Migrations, down.Migrations)
+	suite.Require().NoError(err)
+	suite.Require().NoError(mig.MigrateToLatest(context.Background()))
+
+	suite.DB = act
+}
+
+func (suite *TicksSuite) SetupTest() {
+	db := suite.DB.(*Activities)
+	suite.Require().NoError(db.Reset(context.Background()))
+}
+
+// createTestDBClient tries to create a new Activities client with backoff retry logic.
+func createTestDBClient(ctx context.Context) (*Activities, error) {
+	callback := func() (*Activities, error) {
+		return New(ctx, viper.GetString(configs.EnvSQLDSN))
+	}
+	return backoff.Retry(ctx, callback,
+		backoff.WithBackOff(backoff.NewExponentialBackOff()),
+		backoff.WithMaxTries(10),
+	)
+}
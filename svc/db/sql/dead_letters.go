@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/db/sql/entities"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CreateDeadLetterActivity records a failed delivery. If it has already been
+// recorded, the existing dead letter is kept and its ID is returned.
+func (a *Activities) CreateDeadLetterActivity(
+	ctx context.Context,
+	params db.CreateDeadLetterActivityParams,
+) (db.CreateDeadLetterActivityResults, error) {
+	// Convert the dead letter from the model to the entity
+	var e entities.DeadLetter
+	if err := e.FromModel(params.DeadLetter); err != nil {
+		return db.CreateDeadLetterActivityResults{}, err
+	}
+
+	// Insert the dead letter, or get the existing one for the same delivery
+	var id int64
+	err := a.db.QueryRowxContext(ctx,
+		`WITH inserted AS (
+			INSERT INTO dead_letters
+				(delivery_id, requester_id, exchange, pair, kind, target, ticks,
+				error, attempts, failed_at, redelivered_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT (delivery_id) DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM inserted
+		UNION ALL
+		SELECT id FROM dead_letters WHERE delivery_id = $1
+		LIMIT 1`,
+		e.DeliveryID, e.RequesterID, e.Exchange, e.Pair, e.Kind, e.Target, e.Ticks,
+		e.Error, e.Attempts, e.FailedAt, e.RedeliveredAt,
+	).Scan(&id)
+	if err != nil {
+		return db.CreateDeadLetterActivityResults{}, fmt.Errorf("inserting dead letter: %w", err)
+	}
+
+	return db.CreateDeadLetterActivityResults{
+		ID: id,
+	}, nil
+}
+
+// ReadDeadLettersActivity reads the failed deliveries.
+func (a *Activities) ReadDeadLettersActivity(
+	ctx context.Context,
+	params db.ReadDeadLettersActivityParams,
+) (db.ReadDeadLettersActivityResults, error) {
+	// Set artificial limit if there is none
+	if params.Limit == 0 {
+		params.Limit = math.MaxInt32
+	}
+
+	// Build the query
+	query := `SELECT id, delivery_id, requester_id, exchange, pair, kind, target, ticks,
+			error, attempts, failed_at, redelivered_at
+		FROM dead_letters
+		WHERE id > $1`
+	args := []interface{}{params.AfterID}
+	if len(params.IDs) > 0 {
+		args = append(args, pq.Array(params.IDs))
+		query += fmt.Sprintf(" AND id = ANY($%d)", len(args))
+	}
+	if params.RequesterID != uuid.Nil {
+		args = append(args, params.RequesterID)
+		query += fmt.Sprintf(" AND requester_id = $%d", len(args))
+	}
+	if params.Exchange != "" {
+		args = append(args, params.Exchange)
+		query += fmt.Sprintf(" AND exchange = $%d", len(args))
+	}
+	if params.Pair != "" {
+		args = append(args, params.Pair)
+		query += fmt.Sprintf(" AND pair = $%d", len(args))
+	}
+	if !params.IncludeRedelivered {
+		query += " AND redelivered_at IS NULL"
+	}
+	query += fmt.Sprintf(" ORDER BY id ASC LIMIT $%d", len(args)+1)
+	args = append(args, params.Limit)
+
+	// Query the dead letters
+	rows, err := a.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return db.ReadDeadLettersActivityResults{}, fmt.Errorf("querying dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	// Loop through the results
+	deadLetters := make([]api.DeadLetter, 0)
+	for rows.Next() {
+		e := entities.DeadLetter{}
+		if err := rows.StructScan(&e); err != nil {
+			return db.ReadDeadLettersActivityResults{}, fmt.Errorf("scanning dead letter: %w", err)
+		}
+
+		dl, err := e.ToModel()
+		if err != nil {
+			return db.ReadDeadLettersActivityResults{}, err
+		}
+		deadLetters = append(deadLetters, dl)
+	}
+
+	return db.ReadDeadLettersActivityResults{
+		DeadLetters: deadLetters,
+	}, nil
+}
+
+// UpdateDeadLetterActivity updates a failed delivery after a redelivery.
+func (a *Activities) UpdateDeadLetterActivity(
+	ctx context.Context,
+	params db.UpdateDeadLetterActivityParams,
+) (db.UpdateDeadLetterActivityResults, error) {
+	var redeliveredAt interface{}
+	if params.RedeliveredAt != nil {
+		redeliveredAt = params.RedeliveredAt.UTC()
+	}
+
+	res, err := a.db.ExecContext(ctx,
+		`UPDATE dead_letters
+		SET error = $2, attempts = $3, redelivered_at = $4
+		WHERE id = $1`,
+		params.ID, params.Error, params.Attempts, redeliveredAt)
+	if err != nil {
+		return db.UpdateDeadLetterActivityResults{}, fmt.Errorf("updating dead letter: %w", err)
+	}
+
+	// Check that the dead letter exists
+	if count, err := res.RowsAffected(); err != nil {
+		return db.UpdateDeadLetterActivityResults{}, fmt.Errorf("updating dead letter: %w", err)
+	} else if count == 0 {
+		return db.UpdateDeadLetterActivityResults{}, fmt.Errorf("dead letter %d not found", params.ID)
+	}
+
+	return db.UpdateDeadLetterActivityResults{}, nil
+}
@@ -0,0 +1,115 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+)
+
+// DeadLetterTarget is the entity for the target of a failed delivery.
+type DeadLetterTarget struct {
+	Callback runtime.CallbackWorkflow `json:"callback"`
+	Signal   api.SignalTarget         `json:"signal"`
+	Batch    bool                     `json:"batch,omitempty"`
+}
+
+// DeadLetter is the entity for a failed delivery of ticks.
+type DeadLetter struct {
+	ID            int64      `db:"id"`
+	DeliveryID    string     `db:"delivery_id"`
+	RequesterID   uuid.UUID  `db:"requester_id"`
+	Exchange      string     `db:"exchange"`
+	Pair          string     `db:"pair"`
+	Kind          string     `db:"kind"`
+	Target        []byte     `db:"target"`
+	Ticks         []byte     `db:"ticks"`
+	Error         string     `db:"error"`
+	Attempts      int        `db:"attempts"`
+	FailedAt      time.Time  `db:"failed_at"`
+	RedeliveredAt *time.Time `db:"redelivered_at"`
+}
+
+// FromModel will convert a dead letter model to a dead letter entity.
+func (dl *DeadLetter) FromModel(model api.DeadLetter) error {
+	// Check that there are ticks and that the delivery is identified
+	if len(model.Ticks) == 0 {
+		return fmt.Errorf("dead letter has no tick")
+	}
+	if model.DeliveryID == "" {
+		return fmt.Errorf("dead letter has no delivery ID")
+	}
+
+	dl.ID = model.ID
+	dl.DeliveryID = model.DeliveryID
+	dl.RequesterID = model.RequesterID
+	dl.Exchange = model.Exchange
+	dl.Pair = model.Pair
+	dl.Kind = string(model.Kind)
+	dl.Error = model.Error
+	dl.Attempts = model.Attempts
+	dl.FailedAt = model.FailedAt.UTC()
+	if model.RedeliveredAt != nil {
+		redeliveredAt := model.RedeliveredAt.UTC()
+		dl.RedeliveredAt = &redeliveredAt
+	}
+
+	// Target and ticks
+	target, err := json.Marshal(DeadLetterTarget{
+		Callback: model.Callback,
+		Signal:   model.Signal,
+		Batch:    model.Batch,
+	})
+	if err != nil {
+		return fmt.Errorf("from dead letter model to entity: %w", err)
+	}
+	dl.Target = target
+
+	ticks, err := json.Marshal(model.Ticks)
+	if err != nil {
+		return fmt.Errorf("from dead letter model to entity: %w", err)
+	}
+	dl.Ticks = ticks
+
+	return nil
+}
+
+// ToModel will convert a dead letter entity to a dead letter model.
+func (dl DeadLetter) ToModel() (api.DeadLetter, error) {
+	// Target and ticks
+	var target DeadLetterTarget
+	if err := json.Unmarshal(dl.Target, &target); err != nil {
+		return api.DeadLetter{}, fmt.Errorf("from dead letter entity to model: %w", err)
+	}
+
+	var ticks []tick.Tick
+	if err := json.Unmarshal(dl.Ticks, &ticks); err != nil {
+		return api.DeadLetter{}, fmt.Errorf("from dead letter entity to model: %w", err)
+	}
+
+	model := api.DeadLetter{
+		ID:          dl.ID,
+		DeliveryID:  dl.DeliveryID,
+		RequesterID: dl.RequesterID,
+		Exchange:    dl.Exchange,
+		Pair:        dl.Pair,
+		Kind:        tick.Kind(dl.Kind),
+		Callback:    target.Callback,
+		Signal:      target.Signal,
+		Batch:       target.Batch,
+		Ticks:       ticks,
+		Error:       dl.Error,
+		Attempts:    dl.Attempts,
+		FailedAt:    dl.FailedAt.UTC(),
+	}
+	if dl.RedeliveredAt != nil {
+		redeliveredAt := dl.RedeliveredAt.UTC()
+		model.RedeliveredAt = &redeliveredAt
+	}
+
+	return model, nil
+}
@@ -0,0 +1,78 @@
+//go:build unit
+// +build unit
+
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestDeadLettersSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettersSuite))
+}
+
+type DeadLettersSuite struct {
+	suite.Suite
+}
+
+func (suite *DeadLettersSuite) TestModelRoundTrip() {
+	t := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+	redeliveredAt := t.Add(time.Hour)
+	model := api.DeadLetter{
+		ID:          1,
+		DeliveryID:  "SignalTicks-1",
+		RequesterID: uuid.New(),
+		Exchange:    "exchange",
+		Pair:        "ETH-USDC",
+		Kind:        tick.KindBook,
+		Signal:      api.SignalTarget{WorkflowID: "Consumer", SignalName: api.DefaultTicksSignalName},
+		Batch:       true,
+		Ticks: []tick.Tick{
+			tick.FromExactBook("exchange", "ETH-USDC", t,
+				decimal.RequireFromString("0.123456789012345678"), decimal.NewFromInt(1),
+				decimal.RequireFromString("0.123456789012345679"), decimal.NewFromInt(2)),
+			tick.FromGap("exchange", "ETH-USDC", t, t.Add(time.Minute)),
+		},
+		Error:         "failing callback",
+		Attempts:      3,
+		FailedAt:      t,
+		RedeliveredAt: &redeliveredAt,
+	}
+
+	var e DeadLetter
+	suite.Require().NoError(e.FromModel(model))
+	res, err := e.ToModel()
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(model.DeliveryID, res.DeliveryID)
+	suite.Require().Equal(model.RequesterID, res.RequesterID)
+	suite.Require().Equal(model.Signal, res.Signal)
+	suite.Require().True(res.Batch)
+	suite.Require().Equal(model.Attempts, res.Attempts)
+	suite.Require().Equal(model.FailedAt, res.FailedAt)
+	suite.Require().Equal(redeliveredAt, *res.RedeliveredAt)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().True(model.Ticks[0].Exact.Equal(res.Ticks[0].Exact))
+	suite.Require().True(res.Ticks[1].IsGap())
+	suite.Require().Equal(model.Ticks[1].GapStart, res.Ticks[1].GapStart)
+}
+
+func (suite *DeadLettersSuite) TestFromModelWithoutTicks() {
+	var e DeadLetter
+	suite.Require().Error(e.FromModel(api.DeadLetter{RequesterID: uuid.New()}))
+}
+
+func (suite *DeadLettersSuite) TestFromModelWithoutDeliveryID() {
+	var e DeadLetter
+	suite.Require().Error(e.FromModel(api.DeadLetter{
+		RequesterID: uuid.New(),
+		Ticks:       []tick.Tick{tick.FromBook("exchange", "ETH-USDC", time.Now(), 1, 1, 2, 1)},
+	}))
+}
@@ -0,0 +1,160 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+// TickData is the entity for a tick data.
+type TickData struct {
+	Price    decimal.Decimal `json:"price"`
+	Bid      decimal.Decimal `json:"bid"`
+	Ask      decimal.Decimal `json:"ask"`
+	BidQty   decimal.Decimal `json:"bid_qty"`
+	AskQty   decimal.Decimal `json:"ask_qty"`
+	Qty      decimal.Decimal `json:"qty"`
+	Side     tick.Side       `json:"side,omitempty"`
+	GapStart *time.Time      `json:"gap_start,omitempty"`
+}
+
+// Tick is the entity for a tick.
+type Tick struct {
+	ID       int64     `db:"id"`
+	Exchange string    `db:"exchange"`
+	Pair     string    `db:"pair"`
+	Feed     string    `db:"feed"`
+	Kind     string    `db:"kind"`
+	Time     time.Time `db:"time"`
+	// TradeID is the exchange ID of the trade, telling apart the trades with
+	// the same data.
+	TradeID string `db:"trade_id"`
+	// Hash is the hash of the data, identifying the tick with the other
+	// fields so it is persisted only once.
+	Hash string `db:"hash"`
+	Data []byte `db:"data"`
+}
+
+// FromModel will convert a tick model to a tick entity. The feed is the kind
+// of the feed a gap tick comes from, other ticks come from the feed of their kind.
+func (t *Tick) FromModel(model tick.Tick, feed tick.Kind) error {
+	// Check that the time is not zero
+	if model.Time.IsZero() {
+		return fmt.Errorf("tick time is zero")
+	}
+
+	t.Exchange = model.Exchange
+	t.Pair = model.Pair
+	t.Kind = string(model.Kind.OrDefault())
+	t.Feed = t.Kind
+	if model.IsGap() {
+		t.Feed = string(feed.OrDefault())
+	}
+	t.Time = model.Time.UTC()
+	t.TradeID = model.TradeID
+
+	// Tick data
+	data := TickData{
+		Price:  model.Exact.Price,
+		Bid:    model.Exact.Bid,
+		Ask:    model.Exact.Ask,
+		BidQty: model.Exact.BidQty,
+		AskQty: model.Exact.AskQty,
+		Qty:    model.Exact.Qty,
+		Side:   model.Side,
+	}
+	if !model.GapStart.IsZero() {
+		gapStart := model.GapStart.UTC()
+		data.GapStart = &gapStart
+	}
+
+	dataByte, err := json.Marshal(&data)
+	if err != nil {
+		return fmt.Errorf("from tick model to entity: %w", err)
+	}
+	t.Data = dataByte
+	hash := sha256.Sum256(dataByte)
+	t.Hash = hex.EncodeToString(hash[:])
+
+	return nil
+}
+
+// ToModel will convert a tick entity to a tick model.
+func (t Tick) ToModel() (tick.Tick, error) {
+	// Tick data
+	data := TickData{}
+	if err := json.Unmarshal(t.Data, &data); err != nil {
+		return tick.Tick{}, fmt.Errorf("from tick entity to model: %w", err)
+	}
+
+	exact := tick.Exact{
+		Price:  data.Price,
+		Bid:    data.Bid,
+		Ask:    data.Ask,
+		BidQty: data.BidQty,
+		AskQty: data.AskQty,
+		Qty:    data.Qty,
+	}
+	price, bid, ask, bidQty, askQty, qty := exact.Float()
+
+	model := tick.Tick{
+		Time:     t.Time.UTC(),
+		Kind:     tick.Kind(t.Kind),
+		Pair:     t.Pair,
+		Price:    price,
+		Bid:      bid,
+		Ask:      ask,
+		BidQty:   bidQty,
+		AskQty:   askQty,
+		Qty:      qty,
+		Side:     data.Side,
+		Exchange: t.Exchange,
+		TradeID:  t.TradeID,
+		Exact:    exact,
+	}
+	if data.GapStart != nil {
+		model.GapStart = data.GapStart.UTC()
+	}
+
+	return model, nil
+}
+
+// FromEntitiesToMap will convert a list of tick entities to a map.
+func FromEntitiesToMap(entities []Tick) []map[string]interface{} {
+	maps := make([]map[string]interface{}, 0, len(entities))
+
+	for _, e := range entities {
+		maps = append(maps, map[string]interface{}{
+			"exchange": e.Exchange,
+			"pair":     e.Pair,
+			"feed":     e.Feed,
+			"kind":     e.Kind,
+			"time":     e.Time,
+			"trade_id": e.TradeID,
+			"hash":     e.Hash,
+			"data":     e.Data,
+		})
+	}
+
+	return maps
+}
+
+// FromModelListToEntityList will convert a tick model list to a tick entity list.
+// The feed is the kind of the feed the gap ticks come from.
+func FromModelListToEntityList(ticks []tick.Tick, feed tick.Kind) ([]Tick, error) {
+	entities := make([]Tick, 0, len(ticks))
+	for _, model := range ticks {
+		e := Tick{}
+		if err := e.FromModel(model, feed); err != nil {
+			return nil, err
+		}
+		entities = append(entities, e)
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,73 @@
+//go:build unit
+// +build unit
+
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTicksSuite(t *testing.T) {
+	suite.Run(t, new(TicksSuite))
+}
+
+type TicksSuite struct {
+	suite.Suite
+}
+
+func (suite *TicksSuite) TestModelRoundTrip() {
+	t := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+	trade := tick.FromExactTrade("exchange", "ETH-USDC", t,
+		decimal.RequireFromString("1.5"), decimal.RequireFromString("0.1"), tick.SideSell)
+	trade.TradeID = "42"
+	models := []tick.Tick{
+		trade,
+		tick.FromExactBook("exchange", "ETH-USDC", t,
+			decimal.RequireFromString("0.123456789012345678"), decimal.NewFromInt(1),
+			decimal.RequireFromString("0.123456789012345679"), decimal.NewFromInt(2)),
+		tick.FromExactTrade("exchange", "ETH-USDC", t,
+			decimal.RequireFromString("1.5"), decimal.RequireFromString("0.1"), tick.SideBuy),
+		tick.FromGap("exchange", "ETH-USDC", t.Add(-time.Minute), t),
+	}
+
+	for _, model := range models {
+		var e Tick
+		suite.Require().NoError(e.FromModel(model, tick.KindTrade))
+		suite.Require().Equal(string(model.Kind), e.Kind)
+		if model.IsGap() {
+			suite.Require().Equal(string(tick.KindTrade), e.Feed)
+		} else {
+			suite.Require().Equal(e.Kind, e.Feed)
+		}
+
+		res, err := e.ToModel()
+		suite.Require().NoError(err)
+		suite.Require().True(model.Exact.Equal(res.Exact), "%v != %v", model.Exact, res.Exact)
+
+		model.Exact, res.Exact = tick.Exact{}, tick.Exact{}
+		suite.Require().Equal(model, res)
+	}
+}
+
+func (suite *TicksSuite) TestFromModelWithNoTime() {
+	var e Tick
+	suite.Require().Error(e.FromModel(tick.Tick{Exchange: "exchange", Pair: "ETH-USDC"}, tick.KindBook))
+}
+
+func (suite *TicksSuite) TestHash() {
+	t := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+	var first, same, other Tick
+	suite.Require().NoError(first.FromModel(tick.FromBook("exchange", "ETH-USDC", t, 1, 1, 2, 1), tick.KindBook))
+	suite.Require().NoError(same.FromModel(tick.FromBook("exchange", "ETH-USDC", t, 1, 1, 2, 1), tick.KindBook))
+	suite.Require().NoError(other.FromModel(tick.FromBook("exchange", "ETH-USDC", t, 1, 1, 3, 1), tick.KindBook))
+
+	// The same tick has the same hash to be persisted only once
+	suite.Require().Len(first.Hash, 64)
+	suite.Require().Equal(first.Hash, same.Hash)
+	suite.Require().NotEqual(first.Hash, other.Hash)
+}
@@ -0,0 +1,203 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrInvalidMigrationName is returned when a migration file name does not start
+// with its numeric ID (i.e. "<id>.<name>.sql").
+var ErrInvalidMigrationName = errors.New("invalid migration name")
+
+// migration is a SQL migration with its up and down scripts.
+type migration struct {
+	ID   int
+	Up   string
+	Down string
+}
+
+// Migrator applies and rolls back the SQL migrations of a database.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []migration
+}
+
+// NewMigrator creates a new migrator from the up and down migrations files and
+// creates the migrations table if it does not exist.
+func NewMigrator(ctx context.Context, db *sqlx.DB, up, down fs.FS) (*Migrator, error) {
+	// Read migrations
+	upScripts, err := readScripts(up)
+	if err != nil {
+		return nil, fmt.Errorf("reading up migrations: %w", err)
+	}
+	downScripts, err := readScripts(down)
+	if err != nil {
+		return nil, fmt.Errorf("reading down migrations: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(upScripts))
+	for id, script := range upScripts {
+		migrations = append(migrations, migration{
+			ID:   id,
+			Up:   script,
+			Down: downScripts[id],
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+
+	// Create the migrations table
+	_, err = db.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS migrations (
+			id BIGINT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			CONSTRAINT pk_migrations PRIMARY KEY (id)
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// MigrateToLatest applies all the migrations that are not applied yet.
+func (m *Migrator) MigrateToLatest(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.MigrateTo(ctx, m.migrations[len(m.migrations)-1].ID)
+}
+
+// MigrateTo applies the migrations that are not applied yet, up to the given ID.
+func (m *Migrator) MigrateTo(ctx context.Context, id int) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.migrations {
+		if mig.ID > id {
+			break
+		} else if applied[mig.ID] {
+			continue
+		}
+
+		if err := m.execute(ctx, mig.Up,
+			`INSERT INTO migrations (id) VALUES ($1)`, mig.ID); err != nil {
+			return fmt.Errorf("applying migration %d: %w", mig.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Rollback rolls back the last applied migration.
+func (m *Migrator) Rollback(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if applied[m.migrations[i].ID] {
+			return m.rollback(ctx, m.migrations[i])
+		}
+	}
+
+	return nil
+}
+
+// RollbackUntil rolls back the applied migrations until the given ID (included).
+func (m *Migrator) RollbackUntil(ctx context.Context, id int) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && m.migrations[i].ID >= id; i-- {
+		if !applied[m.migrations[i].ID] {
+			continue
+		}
+
+		if err := m.rollback(ctx, m.migrations[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) rollback(ctx context.Context, mig migration) error {
+	if err := m.execute(ctx, mig.Down,
+		`DELETE FROM migrations WHERE id = $1`, mig.ID); err != nil {
+		return fmt.Errorf("rolling back migration %d: %w", mig.ID, err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]bool, error) {
+	var ids []int
+	if err := m.db.SelectContext(ctx, &ids, `SELECT id FROM migrations`); err != nil {
+		return nil, fmt.Errorf("reading applied migrations: %w", err)
+	}
+
+	applied := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
+}
+
+// execute runs the script and records it in the migrations table in a
+// single transaction.
+func (m *Migrator) execute(ctx context.Context, script, record string, id int) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func readScripts(fsys fs.FS) (map[int]string, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make(map[int]string, len(names))
+	for _, name := range names {
+		idStr, _, _ := strings.Cut(name, ".")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMigrationName, name)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		scripts[id] = string(content)
+	}
+
+	return scripts, nil
+}
@@ -0,0 +1,39 @@
+//go:build unit
+// +build unit
+
+package migrator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMigratorSuite(t *testing.T) {
+	suite.Run(t, new(MigratorSuite))
+}
+
+type MigratorSuite struct {
+	suite.Suite
+}
+
+func (suite *MigratorSuite) TestReadScripts() {
+	scripts, err := readScripts(fstest.MapFS{
+		"20250102000000.second.sql": {Data: []byte("SELECT 2;")},
+		"20250101000000.init.sql":   {Data: []byte("SELECT 1;")},
+		"embed.go":                  {Data: []byte("package up")},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(map[int]string{
+		20250101000000: "SELECT 1;",
+		20250102000000: "SELECT 2;",
+	}, scripts)
+}
+
+func (suite *MigratorSuite) TestReadScriptsInvalidName() {
+	_, err := readScripts(fstest.MapFS{
+		"init.sql": {Data: []byte("SELECT 1;")},
+	})
+	suite.Require().ErrorIs(err, ErrInvalidMigrationName)
+}
@@ -0,0 +1,407 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+)
+
+// TicksSuite is the test suite for the ticks database.
+type TicksSuite struct {
+	suite.Suite
+	DB DB
+}
+
+func (suite *TicksSuite) bookTick(exchange, pair string, t time.Time, bid, ask string) tick.Tick {
+	return tick.FromExactBook(exchange, pair, t,
+		decimal.RequireFromString(bid), decimal.NewFromInt(1),
+		decimal.RequireFromString(ask), decimal.NewFromInt(2))
+}
+
+// TestCreate tests the case where the ticks are created.
+func (suite *TicksSuite) TestCreate() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := []tick.Tick{
+		suite.bookTick("exchange", "ETH-USDC", t, "0.123456789012345678", "0.123456789012345679"),
+		tick.FromExactTrade("exchange", "ETH-USDC", t,
+			decimal.RequireFromString("1.5"), decimal.RequireFromString("0.1"), tick.SideSell),
+	}
+
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: ticks,
+	})
+	suite.Require().NoError(err)
+
+	// Read book ticks
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindBook,
+		Start:    t.Add(-time.Hour),
+		End:      t.Add(time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+	suite.Require().True(ticks[0].Exact.Equal(res.Ticks[0].Exact), "%v != %v", ticks[0].Exact, res.Ticks[0].Exact)
+	suite.Require().Equal(ticks[0].Time, res.Ticks[0].Time)
+	suite.Require().Equal(tick.KindBook, res.Ticks[0].Kind)
+	suite.Require().Equal(ticks[0].Bid, res.Ticks[0].Bid)
+
+	// Read trade ticks
+	res, err = suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindTrade,
+		Start:    t.Add(-time.Hour),
+		End:      t.Add(time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+	suite.Require().True(ticks[1].Exact.Equal(res.Ticks[0].Exact))
+	suite.Require().Equal(tick.SideSell, res.Ticks[0].Side)
+}
+
+// TestCreateTwice tests the case where the same ticks are created twice, as
+// when the activity is retried: they are persisted only once.
+func (suite *TicksSuite) TestCreateTwice() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := []tick.Tick{suite.bookTick("exchange", "ETH-USDC", t, "1", "2")}
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+			Ticks: ticks,
+		})
+		suite.Require().NoError(err)
+	}
+
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Start:    t,
+		End:      t,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+}
+
+// TestCreateSameTrades tests the case where different trades have the same
+// time, price, quantity and side: they are told apart by their trade ID.
+func (suite *TicksSuite) TestCreateSameTrades() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := make([]tick.Tick, 0, 2)
+	for _, id := range []string{"1", "2"} {
+		trade := tick.FromExactTrade("exchange", "ETH-USDC", t,
+			decimal.RequireFromString("1.5"), decimal.RequireFromString("0.1"), tick.SideSell)
+		trade.TradeID = id
+		ticks = append(ticks, trade)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+			Ticks: ticks,
+		})
+		suite.Require().NoError(err)
+	}
+
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindTrade,
+		Start:    t,
+		End:      t,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().Equal("1", res.Ticks[0].TradeID)
+	suite.Require().Equal("2", res.Ticks[1].TradeID)
+}
+
+// TestCreateWithNoTime tests the case where the tick to create does not
+// have a time set.
+func (suite *TicksSuite) TestCreateWithNoTime() {
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: []tick.Tick{suite.bookTick("exchange", "ETH-USDC", time.Time{}, "1", "2")},
+	})
+	suite.Require().Error(err)
+}
+
+// TestRead tests the case where the ticks are read with filters and limit.
+func (suite *TicksSuite) TestRead() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := []tick.Tick{
+		suite.bookTick("exchange", "ETH-USDC", t, "1", "2"),
+		suite.bookTick("exchange", "ETH-USDC", t.Add(time.Second), "2", "3"),
+		tick.FromGap("exchange", "ETH-USDC", t.Add(time.Second), t.Add(2*time.Second)),
+		suite.bookTick("exchange", "ETH-USDC", t.Add(3*time.Second), "3", "4"),
+		suite.bookTick("exchange2", "ETH-USDC", t.Add(time.Second), "4", "5"),
+		suite.bookTick("exchange", "BTC-USDC", t.Add(time.Second), "5", "6"),
+	}
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: ticks,
+	})
+	suite.Require().NoError(err)
+
+	// Read only the centered ticks, including the gap
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Start:    t.Add(time.Second),
+		End:      t.Add(2 * time.Second),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().Equal(2.0, res.Ticks[0].Bid)
+	suite.Require().True(res.Ticks[1].IsGap())
+	suite.Require().Equal(ticks[2].GapStart, res.Ticks[1].GapStart)
+
+	// Read with limit
+	res, err = suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Start:    t,
+		End:      t.Add(time.Hour),
+		Limit:    2,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().Equal(1.0, res.Ticks[0].Bid)
+	suite.Require().Equal(2.0, res.Ticks[1].Bid)
+}
+
+// TestReadGapsOfFeed tests the case where the gaps of different feeds are read
+// only with the ticks of their feed.
+func (suite *TicksSuite) TestReadGapsOfFeed() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	gap := tick.FromGap("exchange", "ETH-USDC", t, t.Add(time.Second))
+	for _, feed := range []tick.Kind{tick.KindBook, tick.KindTrade} {
+		_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+			Ticks: []tick.Tick{gap},
+			Feed:  feed,
+		})
+		suite.Require().NoError(err)
+	}
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: []tick.Tick{suite.bookTick("exchange", "ETH-USDC", t.Add(2*time.Second), "1", "2")},
+		Feed:  tick.KindBook,
+	})
+	suite.Require().NoError(err)
+
+	// Read the trade ticks, only with the gap of the trade feed
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindTrade,
+		Start:    t,
+		End:      t.Add(time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+	suite.Require().True(res.Ticks[0].IsGap())
+
+	// Read the book ticks, only with the gap of the book feed
+	res, err = suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindBook,
+		Start:    t,
+		End:      t.Add(time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().True(res.Ticks[0].IsGap())
+	suite.Require().False(res.Ticks[1].IsGap())
+}
+
+// TestReadEmpty tests the case where there is no tick to read.
+func (suite *TicksSuite) TestReadEmpty() {
+	t, err := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	suite.Require().NoError(err)
+	res, err := suite.DB.ReadTicksActivity(context.Background(), ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Start:    t.Add(-time.Hour),
+		End:      t.Add(time.Hour),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 0)
+}
+
+// TestReadAfter tests the case where the ticks are read page by page.
+func (suite *TicksSuite) TestReadAfter() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := []tick.Tick{
+		suite.bookTick("exchange", "ETH-USDC", t, "1", "2"),
+		suite.bookTick("exchange", "ETH-USDC", t, "2", "3"),
+		suite.bookTick("exchange", "ETH-USDC", t.Add(time.Second), "3", "4"),
+	}
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: ticks,
+	})
+	suite.Require().NoError(err)
+
+	// Read pages of two ticks
+	params := ReadTicksActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Start:    t,
+		End:      t.Add(time.Hour),
+		Limit:    2,
+	}
+	res, err := suite.DB.ReadTicksActivity(context.Background(), params)
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 2)
+	suite.Require().Equal(1.0, res.Ticks[0].Bid)
+	suite.Require().Equal(2.0, res.Ticks[1].Bid)
+	suite.Require().NotNil(res.Last)
+
+	params.After = res.Last
+	res, err = suite.DB.ReadTicksActivity(context.Background(), params)
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+	suite.Require().Equal(3.0, res.Ticks[0].Bid)
+
+	params.After = res.Last
+	res, err = suite.DB.ReadTicksActivity(context.Background(), params)
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 0)
+	suite.Require().Nil(res.Last)
+}
+
+// TestReadLastTick tests the case where the last tick is read.
+func (suite *TicksSuite) TestReadLastTick() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	ticks := []tick.Tick{
+		suite.bookTick("exchange", "ETH-USDC", t, "1", "2"),
+		suite.bookTick("exchange", "ETH-USDC", t.Add(time.Second), "2", "3"),
+		tick.FromGap("exchange", "ETH-USDC", t.Add(time.Second), t.Add(2*time.Second)),
+		tick.FromExactTrade("exchange", "ETH-USDC", t.Add(3*time.Second),
+			decimal.RequireFromString("1.5"), decimal.RequireFromString("0.1"), tick.SideBuy),
+		suite.bookTick("exchange", "BTC-USDC", t.Add(3*time.Second), "3", "4"),
+	}
+	_, err := suite.DB.CreateTicksActivity(context.Background(), CreateTicksActivityParams{
+		Ticks: ticks,
+	})
+	suite.Require().NoError(err)
+
+	// Read last book tick, ignoring gaps and other kinds
+	res, err := suite.DB.ReadLastTickActivity(context.Background(), ReadLastTickActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+	})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(res.Tick)
+	suite.Require().Equal(2.0, res.Tick.Bid)
+	suite.Require().Equal(t.Add(time.Second), res.Tick.Time)
+
+	// Read last trade tick
+	res, err = suite.DB.ReadLastTickActivity(context.Background(), ReadLastTickActivityParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		Kind:     tick.KindTrade,
+	})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(res.Tick)
+	suite.Require().Equal(1.5, res.Tick.Price)
+
+	// Read inexistant tick
+	res, err = suite.DB.ReadLastTickActivity(context.Background(), ReadLastTickActivityParams{
+		Exchange: "exchange",
+		Pair:     "SOL-USDC",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Nil(res.Tick)
+}
+
+// TestDeadLetters tests the case where failed deliveries are recorded, read
+// and updated after a redelivery.
+func (suite *TicksSuite) TestDeadLetters() {
+	t, _ := time.Parse(time.RFC3339, "1993-11-15T11:29:00Z")
+	requester := uuid.New()
+	dl := api.DeadLetter{
+		DeliveryID:  "SendTick-first",
+		RequesterID: requester,
+		Exchange:    "exchange",
+		Pair:        "ETH-USDC",
+		Kind:        tick.KindBook,
+		Callback:    runtime.CallbackWorkflow{Name: "Callback", TaskQueueName: "queue"},
+		Ticks:       []tick.Tick{suite.bookTick("exchange", "ETH-USDC", t, "0.123456789012345678", "1")},
+		Error:       "failing callback",
+		Attempts:    1,
+		FailedAt:    t,
+	}
+
+	// Create dead letters for two requesters
+	first, err := suite.DB.CreateDeadLetterActivity(context.Background(), CreateDeadLetterActivityParams{
+		DeadLetter: dl,
+	})
+	suite.Require().NoError(err)
+	other := dl
+	other.DeliveryID = "SendTick-other"
+	other.RequesterID = uuid.New()
+	_, err = suite.DB.CreateDeadLetterActivity(context.Background(), CreateDeadLetterActivityParams{
+		DeadLetter: other,
+	})
+	suite.Require().NoError(err)
+
+	// Record the first delivery again, which keeps the existing dead letter
+	again := dl
+	again.Error = "other error"
+	recorded, err := suite.DB.CreateDeadLetterActivity(context.Background(), CreateDeadLetterActivityParams{
+		DeadLetter: again,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(first.ID, recorded.ID)
+
+	// Read the dead letters of the first requester
+	res, err := suite.DB.ReadDeadLettersActivity(context.Background(), ReadDeadLettersActivityParams{
+		RequesterID: requester,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.DeadLetters, 1)
+	suite.Require().Equal(first.ID, res.DeadLetters[0].ID)
+	suite.Require().Equal(dl.DeliveryID, res.DeadLetters[0].DeliveryID)
+	suite.Require().Equal(dl.Callback, res.DeadLetters[0].Callback)
+	suite.Require().Equal("failing callback", res.DeadLetters[0].Error)
+	suite.Require().Equal(t, res.DeadLetters[0].FailedAt)
+	suite.Require().Nil(res.DeadLetters[0].RedeliveredAt)
+	suite.Require().Len(res.DeadLetters[0].Ticks, 1)
+	suite.Require().True(dl.Ticks[0].Exact.Equal(res.DeadLetters[0].Ticks[0].Exact))
+
+	// Mark the first one as redelivered
+	redeliveredAt := t.Add(time.Hour)
+	_, err = suite.DB.UpdateDeadLetterActivity(context.Background(), UpdateDeadLetterActivityParams{
+		ID:            first.ID,
+		Error:         "failing callback",
+		Attempts:      2,
+		RedeliveredAt: &redeliveredAt,
+	})
+	suite.Require().NoError(err)
+
+	// Check that it is only read when including the redelivered ones
+	res, err = suite.DB.ReadDeadLettersActivity(context.Background(), ReadDeadLettersActivityParams{})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.DeadLetters, 1)
+	suite.Require().Equal(other.RequesterID, res.DeadLetters[0].RequesterID)
+
+	res, err = suite.DB.ReadDeadLettersActivity(context.Background(), ReadDeadLettersActivityParams{
+		IDs:                []int64{first.ID},
+		IncludeRedelivered: true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.DeadLetters, 1)
+	suite.Require().Equal(2, res.DeadLetters[0].Attempts)
+	suite.Require().NotNil(res.DeadLetters[0].RedeliveredAt)
+	suite.Require().Equal(redeliveredAt, *res.DeadLetters[0].RedeliveredAt)
+
+	// Update an inexistant dead letter
+	_, err = suite.DB.UpdateDeadLetterActivity(context.Background(), UpdateDeadLetterActivityParams{
+		ID: first.ID + 1000,
+	})
+	suite.Require().Error(err)
+}
@@ -0,0 +1,144 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ListDeadLettersWorkflow lists the deliveries of ticks that have failed.
+func (wf *workflows) ListDeadLettersWorkflow(
+	ctx workflow.Context,
+	params api.ListDeadLettersWorkflowParams,
+) (api.ListDeadLettersWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Requested dead letters list",
+		"requester_id", params.RequesterID,
+		"exchange", params.Exchange,
+		"pair", params.Pair)
+
+	// Cap the limit
+	if params.Limit == 0 || params.Limit > api.ListDeadLettersMaxLimit {
+		params.Limit = api.ListDeadLettersMaxLimit
+	}
+
+	// Read the dead letters
+	res, err := wf.readDeadLetters(ctx, db.ReadDeadLettersActivityParams{
+		RequesterID:        params.RequesterID,
+		Exchange:           params.Exchange,
+		Pair:               params.Pair,
+		IncludeRedelivered: params.IncludeRedelivered,
+		AfterID:            params.AfterID,
+		Limit:              params.Limit,
+	})
+	if err != nil {
+		return api.ListDeadLettersWorkflowResults{}, err
+	}
+
+	return api.ListDeadLettersWorkflowResults{
+		DeadLetters: res,
+	}, nil
+}
+
+// RedeliverDeadLettersWorkflow delivers again the ticks whose delivery has
+// failed, to the same callback or workflow. The dead letters already
+// redelivered are skipped.
+func (wf *workflows) RedeliverDeadLettersWorkflow(
+	ctx workflow.Context,
+	params api.RedeliverDeadLettersWorkflowParams,
+) (api.RedeliverDeadLettersWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Requested dead letters redelivery", "ids", params.IDs)
+
+	// Ensure the required parameters are provided
+	if len(params.IDs) == 0 {
+		return api.RedeliverDeadLettersWorkflowResults{}, errors.New("IDs must be provided")
+	}
+	if len(params.IDs) > api.ListDeadLettersMaxLimit {
+		return api.RedeliverDeadLettersWorkflowResults{},
+			fmt.Errorf("cannot redeliver more than %d dead letters at once", api.ListDeadLettersMaxLimit)
+	}
+
+	// Read the dead letters
+	deadLetters, err := wf.readDeadLetters(ctx, db.ReadDeadLettersActivityParams{
+		IDs:                params.IDs,
+		IncludeRedelivered: true,
+	})
+	if err != nil {
+		return api.RedeliverDeadLettersWorkflowResults{}, err
+	}
+
+	// Deliver them again
+	for i, dl := range deadLetters {
+		if dl.RedeliveredAt != nil {
+			continue
+		}
+
+		deadLetters[i], err = wf.redeliverDeadLetter(ctx, dl)
+		if err != nil {
+			return api.RedeliverDeadLettersWorkflowResults{}, err
+		}
+	}
+
+	return api.RedeliverDeadLettersWorkflowResults{
+		DeadLetters: deadLetters,
+	}, nil
+}
+
+// redeliverDeadLetter delivers the ticks of a dead letter again, and updates
+// it with the result of the delivery.
+func (wf *workflows) redeliverDeadLetter(ctx workflow.Context, dl api.DeadLetter) (api.DeadLetter, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Deliver the ticks through a listener with the same target. As the
+	// delivery ID is the same as the failed one, it is not executed twice if
+	// a previous redelivery has succeeded. If another delivery is still being
+	// executed, the dead letter is kept as its success is not known yet.
+	delivery := api.DeliveryOptions{}
+	if dl.Batch {
+		delivery.Batch.MaxSize = uint(len(dl.Ticks))
+	}
+	l := newListener(dl.RequesterID, dl.Callback, dl.Signal, delivery, dl.FailedAt)
+	err := l.deliver(ctx, dl.Ticks, 0)
+
+	// Update the dead letter
+	dl.Attempts++
+	if err == nil {
+		now := workflow.Now(ctx)
+		dl.RedeliveredAt = &now
+	} else {
+		logger.Error("Failed to redeliver dead letter", "id", dl.ID, "error", err)
+		dl.Error = err.Error()
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, db.DefaultActivityOptions())
+	err = workflow.ExecuteActivity(ctx, wf.db.UpdateDeadLetterActivity, db.UpdateDeadLetterActivityParams{
+		ID:            dl.ID,
+		Error:         dl.Error,
+		Attempts:      dl.Attempts,
+		RedeliveredAt: dl.RedeliveredAt,
+	}).Get(ctx, nil)
+	if err != nil {
+		return api.DeadLetter{}, err
+	}
+
+	return dl, nil
+}
+
+func (wf *workflows) readDeadLetters(
+	ctx workflow.Context,
+	params db.ReadDeadLettersActivityParams,
+) ([]api.DeadLetter, error) {
+	ctx = workflow.WithActivityOptions(ctx, db.DefaultActivityOptions())
+
+	var res db.ReadDeadLettersActivityResults
+	err := workflow.ExecuteActivity(ctx, wf.db.ReadDeadLettersActivity, params).Get(ctx, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.DeadLetters, nil
+}
@@ -0,0 +1,178 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/internal/activities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLettersSuite(t *testing.T) {
+	suite.Run(t, new(DeadLettersSuite))
+}
+
+type DeadLettersSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env      *testsuite.TestWorkflowEnvironment
+	received []api.ListenToTicksBatchCallbackWorkflowParams
+	updates  []db.UpdateDeadLetterActivityParams
+}
+
+func (suite *DeadLettersSuite) SetupTest() {
+	ctrl := gomock.NewController(suite.T())
+	wf := &workflows{
+		db: db.NewMockDB(ctrl),
+	}
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	suite.env.RegisterWorkflowWithOptions(wf.RedeliverDeadLettersWorkflow, workflow.RegisterOptions{
+		Name: api.RedeliverDeadLettersWorkflowName,
+	})
+	suite.env.RegisterActivityWithOptions(wf.db.ReadDeadLettersActivity,
+		activity.RegisterOptions{Name: db.ReadDeadLettersActivityName})
+	suite.env.RegisterActivityWithOptions(wf.db.UpdateDeadLetterActivity,
+		activity.RegisterOptions{Name: db.UpdateDeadLetterActivityName})
+	suite.env.RegisterActivity((&activities.Activities{}).GetWorkflowStatusActivity)
+
+	// Record the redelivered ticks and the dead letters updates
+	suite.received = nil
+	suite.updates = nil
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, params api.ListenToTicksBatchCallbackWorkflowParams) error {
+			suite.received = append(suite.received, params)
+			return nil
+		}, workflow.RegisterOptions{Name: "BatchCallback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, _ api.ListenToTicksCallbackWorkflowParams) error {
+			return errors.New("failing callback")
+		}, workflow.RegisterOptions{Name: "FailingCallback"})
+	suite.env.OnActivity(db.UpdateDeadLetterActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params db.UpdateDeadLetterActivityParams) (db.UpdateDeadLetterActivityResults, error) {
+			suite.updates = append(suite.updates, params)
+			return db.UpdateDeadLetterActivityResults{}, nil
+		})
+}
+
+func (suite *DeadLettersSuite) TestRedeliver() {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ticks := []tick.Tick{
+		tick.FromBook("exchange", "ETH-USDC", at, 1, 1, 2, 1),
+		tick.FromBook("exchange", "ETH-USDC", at.Add(time.Second), 2, 1, 3, 1),
+	}
+	requester := uuid.New()
+	deadLetters := []api.DeadLetter{
+		{ID: 1, RequesterID: requester, Callback: runtime.CallbackWorkflow{Name: "BatchCallback"},
+			Batch: true, Ticks: ticks, Attempts: 1},
+		{ID: 2, RequesterID: requester, Callback: runtime.CallbackWorkflow{Name: "FailingCallback"},
+			Ticks: ticks[:1], Attempts: 1},
+		{ID: 3, RequesterID: requester, Callback: runtime.CallbackWorkflow{Name: "FailingCallback"},
+			Ticks: ticks[:1], Attempts: 2, RedeliveredAt: &at},
+	}
+	suite.env.OnActivity(db.ReadDeadLettersActivityName, mock.Anything, db.ReadDeadLettersActivityParams{
+		IDs:                []int64{1, 2, 3},
+		IncludeRedelivered: true,
+	}).Return(db.ReadDeadLettersActivityResults{DeadLetters: deadLetters}, nil)
+
+	suite.env.ExecuteWorkflow(api.RedeliverDeadLettersWorkflowName, api.RedeliverDeadLettersWorkflowParams{
+		IDs: []int64{1, 2, 3},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	var res api.RedeliverDeadLettersWorkflowResults
+	suite.Require().NoError(suite.env.GetWorkflowResult(&res))
+	suite.Require().Len(res.DeadLetters, 3)
+
+	// The batch is redelivered as a whole
+	suite.Require().Len(suite.received, 1)
+	suite.Require().Equal(requester, suite.received[0].RequesterID)
+	suite.Require().Len(suite.received[0].Ticks, 2)
+	suite.Require().NotNil(res.DeadLetters[0].RedeliveredAt)
+	suite.Require().Equal(2, res.DeadLetters[0].Attempts)
+
+	// The failing one is updated with the new error
+	suite.Require().Nil(res.DeadLetters[1].RedeliveredAt)
+	suite.Require().Equal(2, res.DeadLetters[1].Attempts)
+	suite.Require().Contains(res.DeadLetters[1].Error, "failing callback")
+
+	// The already redelivered one is skipped
+	suite.Require().Equal(2, res.DeadLetters[2].Attempts)
+	suite.Require().Len(suite.updates, 2)
+	suite.Require().Equal(int64(1), suite.updates[0].ID)
+	suite.Require().Equal(int64(2), suite.updates[1].ID)
+}
+
+func (suite *DeadLettersSuite) TestRedeliverWithoutIDs() {
+	suite.env.ExecuteWorkflow(api.RedeliverDeadLettersWorkflowName, api.RedeliverDeadLettersWorkflowParams{})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().Error(suite.env.GetWorkflowError())
+}
+
+// redeliverTwice redelivers two dead letters with the same delivery, so that
+// the second one conflicts with the delivery workflow of the first one, whose
+// status is the given one.
+func (suite *DeadLettersSuite) redeliverTwice(status enums.WorkflowExecutionStatus) []api.DeadLetter {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dl := api.DeadLetter{
+		ID:          1,
+		RequesterID: uuid.New(),
+		Callback:    runtime.CallbackWorkflow{Name: "BatchCallback"},
+		Batch:       true,
+		Ticks:       []tick.Tick{tick.FromBook("exchange", "ETH-USDC", at, 1, 1, 2, 1)},
+		Attempts:    1,
+	}
+	same := dl
+	same.ID = 2
+	suite.env.OnActivity(db.ReadDeadLettersActivityName, mock.Anything, mock.Anything).
+		Return(db.ReadDeadLettersActivityResults{DeadLetters: []api.DeadLetter{dl, same}}, nil)
+	suite.env.OnActivity("GetWorkflowStatusActivity", mock.Anything, mock.Anything).
+		Return(activities.GetWorkflowStatusActivityResults{Status: status}, nil)
+
+	suite.env.ExecuteWorkflow(api.RedeliverDeadLettersWorkflowName, api.RedeliverDeadLettersWorkflowParams{
+		IDs: []int64{1, 2},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	var res api.RedeliverDeadLettersWorkflowResults
+	suite.Require().NoError(suite.env.GetWorkflowResult(&res))
+	suite.Require().Len(res.DeadLetters, 2)
+	suite.Require().Len(suite.received, 1)
+	suite.Require().NotNil(res.DeadLetters[0].RedeliveredAt)
+	return res.DeadLetters
+}
+
+func (suite *DeadLettersSuite) TestRedeliverAlreadyDelivered() {
+	deadLetters := suite.redeliverTwice(enums.WORKFLOW_EXECUTION_STATUS_COMPLETED)
+
+	// The completed delivery is confirmed without being executed again
+	suite.Require().NotNil(deadLetters[1].RedeliveredAt)
+}
+
+func (suite *DeadLettersSuite) TestRedeliverWhileDeliveryInProgress() {
+	deadLetters := suite.redeliverTwice(enums.WORKFLOW_EXECUTION_STATUS_RUNNING)
+
+	// The delivery in progress is not confirmed, so the dead letter is kept
+	suite.Require().Nil(deadLetters[1].RedeliveredAt)
+	suite.Require().Equal(2, deadLetters[1].Attempts)
+	suite.Require().Contains(deadLetters[1].Error, ErrDeliveryInProgress.Error())
+}
@@ -31,6 +31,16 @@ func (a *Activities) Name() string {
 	return "aggregator"
 }
 
+// LocalPairs will return the local pairs of the corresponding exchange.
+func (a *Activities) LocalPairs(exchange string) ([]string, bool) {
+	exch, ok := a.exchanges[exchange]
+	if !ok {
+		return nil, false
+	}
+
+	return exch.LocalPairs(exchange)
+}
+
 // Register will register the exchanges aggregator to Temporal.
 func (a *Activities) Register(w worker.Worker) {
 	w.RegisterActivityWithOptions(
@@ -9,12 +9,14 @@ import (
 	"time"
 
 	client "github.com/adshao/go-binance/v2"
+	"github.com/cenkalti/backoff/v5"
 	"github.com/cryptellation/candlesticks/pkg/pair"
 	"github.com/cryptellation/ticks/pkg/temporal"
 	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/cryptellation/ticks/svc/exchanges"
-	"github.com/cryptellation/ticks/svc/internal/signals"
-	"go.temporal.io/api/enums/v1"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets"
+	"github.com/shopspring/decimal"
 	"go.temporal.io/sdk/activity"
 	temporalclient "go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
@@ -24,6 +26,7 @@ import (
 type Activities struct {
 	temporal temporalclient.Client
 	client   *client.Client
+	streams  *streamManager
 }
 
 // New will create a new binance exchanges.
@@ -42,6 +45,7 @@ func New(temporal temporalclient.Client, apiKey, secretKey string) (*Activities,
 	return &Activities{
 		temporal: temporal,
 		client:   c,
+		streams:  newStreamManager(dialCombinedStreams),
 	}, nil
 }
 
@@ -50,6 +54,11 @@ func (a *Activities) Name() string {
 	return ExchangeName
 }
 
+// LocalPairs will return false as binance pairs are defined on the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return nil, false
+}
+
 // Register will register the exchanges.
 func (a *Activities) Register(w worker.Worker) {
 	w.RegisterActivityWithOptions(
@@ -67,80 +76,159 @@ func (a *Activities) ListenSymbolActivity(
 		return exchanges.ListenSymbolResults{}, err
 	}
 
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
 	// Start heartbeat on activity
 	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
 
-	// Listen to binance book ticker
-	var lastBid, lastAsk string
-	done, cancel, err := client.WsBookTickerServe(binanceSymbol, func(event *client.WsBookTickerEvent) {
-		// Skip if same price as last tick
-		if event.BestAskPrice == lastAsk && event.BestBidPrice == lastBid {
-			return
-		}
-		lastAsk = event.BestAskPrice
-		lastBid = event.BestBidPrice
+	// Events are handled outside of the listening, which is stopped through
+	// the context when the ticks cannot be sent anymore
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	// Listen to the stream, reconnecting when it is interrupted
+	err = websockets.ListenWithReconnection(ctx, backoff.NewExponentialBackOff(),
+		func(ctx context.Context, connected func()) error {
+			return a.listen(ctx, stop, binanceSymbol, params, connected)
+		},
+		func(start, end time.Time) {
+			a.signalNewTick(ctx, stop, tick.FromGap(ExchangeName, params.Symbol, start, end), params)
+		})
+	if cause := context.Cause(ctx); errors.Is(cause, signaling.ErrParentNotRunning) {
+		err = cause
+	}
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf("binance listener stopped: %w", err)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+func (a *Activities) listen(
+	ctx context.Context,
+	stop context.CancelCauseFunc,
+	binanceSymbol string,
+	params exchanges.ListenSymbolParams,
+	connected func(),
+) error {
+	// Attach to the combined stream shared with other listeners of the worker
+	sub := a.streams.Attach(params.Kind.OrDefault(), binanceSymbol, a.eventHandler(ctx, stop, params))
+	defer a.streams.Detach(sub)
 
+	// Wait for the combined stream to be connected
+	select {
+	case <-sub.Connected():
+		connected()
+	case err := <-sub.Closed():
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+
+	// Wait for stream to be closed or context to be done
+	select {
+	case err := <-sub.Closed():
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (a *Activities) eventHandler(
+	ctx context.Context,
+	stop context.CancelCauseFunc,
+	params exchanges.ListenSymbolParams,
+) func(event any) {
+	var last client.WsBookTickerEvent
+	return func(event any) {
 		// Convert to tick
-		t, err := toTick(params.Symbol, event.BestAskPrice, event.BestBidPrice)
+		var t tick.Tick
+		var err error
+		switch e := event.(type) {
+		case *client.WsBookTickerEvent:
+			// Skip if same prices and quantities as last tick
+			if e.BestBidPrice == last.BestBidPrice && e.BestBidQty == last.BestBidQty &&
+				e.BestAskPrice == last.BestAskPrice && e.BestAskQty == last.BestAskQty {
+				return
+			}
+			last = *e
+
+			t, err = toBookTick(params.Symbol, e)
+		case *client.WsAggTradeEvent:
+			t, err = toTradeTick(params.Symbol, e)
+		case gapEvent:
+			// Events have been dropped as the ticks were not sent fast enough
+			t = tick.FromGap(ExchangeName, params.Symbol, e.Start, e.End)
+		default:
+			return
+		}
 		if err != nil {
 			return
 		}
 
 		// Send it to main workflow through Signal
-		err = a.temporal.SignalWorkflow(ctx, params.ParentWorkflowID, "", signals.NewTickReceivedSignalName, t)
-		if err != nil {
-			a.handleNewTickSignalError(ctx, err, params)
-		}
-	}, nil)
-	if err != nil {
-		return exchanges.ListenSymbolResults{}, err
+		a.signalNewTick(ctx, stop, t, params)
 	}
+}
 
-	// Wait for context to be done or cancelled
-	select {
-	case <-done:
-		// If done, return error as listener stopped
-		return exchanges.ListenSymbolResults{}, fmt.Errorf("binance listener stopped")
-	case <-ctx.Done():
-		// If context is done, cancel listener and return
-		cancel <- struct{}{}
-		return exchanges.ListenSymbolResults{}, nil
+func (a *Activities) signalNewTick(
+	ctx context.Context,
+	stop context.CancelCauseFunc,
+	t tick.Tick,
+	params exchanges.ListenSymbolParams,
+) {
+	err := signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t)
+	if errors.Is(err, signaling.ErrParentNotRunning) {
+		stop(err)
 	}
 }
 
-func (a *Activities) handleNewTickSignalError(ctx context.Context, ntsErr error, params exchanges.ListenSymbolParams) {
-	// Context was cancelled, this will stop listener
-	if errors.Is(ntsErr, context.Canceled) {
-		return
+func toBookTick(symbol string, event *client.WsBookTickerEvent) (tick.Tick, error) {
+	bid, err := decimal.NewFromString(event.BestBidPrice)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	bidQty, err := decimal.NewFromString(event.BestBidQty)
+	if err != nil {
+		return tick.Tick{}, err
 	}
 
-	// Check if parent workflow is still running
-	desc, err := a.temporal.DescribeWorkflowExecution(ctx, params.ParentWorkflowID, "")
+	ask, err := decimal.NewFromString(event.BestAskPrice)
 	if err != nil {
-		return
-	} else if desc.WorkflowExecutionInfo.Status == enums.WORKFLOW_EXECUTION_STATUS_COMPLETED {
-		// Workflow is already completed
-		return
+		return tick.Tick{}, err
 	}
+
+	askQty, err := decimal.NewFromString(event.BestAskQty)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	return tick.FromExactBook(ExchangeName, symbol, time.Now().UTC(), bid, bidQty, ask, askQty), nil
 }
 
-func toTick(symbol, ask, bid string) (tick.Tick, error) {
-	askPrice, err := strconv.ParseFloat(ask, 64)
+func toTradeTick(symbol string, event *client.WsAggTradeEvent) (tick.Tick, error) {
+	price, err := decimal.NewFromString(event.Price)
 	if err != nil {
 		return tick.Tick{}, err
 	}
 
-	bidPrice, err := strconv.ParseFloat(bid, 64)
+	qty, err := decimal.NewFromString(event.Quantity)
 	if err != nil {
 		return tick.Tick{}, err
 	}
 
-	return tick.Tick{
-		Time:     time.Now().UTC(),
-		Exchange: "binance",
-		Pair:     symbol,
-		Price:    (askPrice + bidPrice) / 2,
-	}, nil
+	// If the buyer is the maker, then the seller is the aggressor
+	side := tick.SideBuy
+	if event.IsBuyerMaker {
+		side = tick.SideSell
+	}
+
+	t := tick.FromExactTrade(ExchangeName, symbol, time.UnixMilli(event.TradeTime).UTC(), price, qty, side)
+	t.TradeID = strconv.FormatInt(event.AggTradeID, 10)
+	return t, nil
 }
 
 func toBinanceSymbol(symbol string) (string, error) {
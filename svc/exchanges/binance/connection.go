@@ -0,0 +1,190 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	client "github.com/adshao/go-binance/v2"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// combinedStreamURL is the endpoint of the binance combined streams, on
+	// which streams are subscribed and unsubscribed while connected.
+	combinedStreamURL = "wss://stream.binance.com:9443/stream"
+	// writeTimeout is the maximum time to send a request on the connection.
+	writeTimeout = 10 * time.Second
+
+	subscribeMethod   = "SUBSCRIBE"
+	unsubscribeMethod = "UNSUBSCRIBE"
+)
+
+// streamConn is a live combined stream connection on which the symbols can be
+// subscribed and unsubscribed without interrupting the other ones.
+type streamConn interface {
+	Subscribe(symbols []string) error
+	Unsubscribe(symbols []string) error
+	Close() error
+}
+
+// dialFunc opens a combined stream connection of the given kind that calls the
+// handler with the symbol of each event. The done channel receives the error
+// that closed the connection.
+type dialFunc func(
+	kind tick.Kind,
+	handler func(symbol string, event any),
+) (conn streamConn, done <-chan error, err error)
+
+// streamRequest is the request sent to subscribe or unsubscribe streams.
+type streamRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int      `json:"id"`
+}
+
+// streamMessage is a message received on a combined stream connection, either
+// an event of a stream or the response to a request.
+type streamMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+	Error  *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// websocketConn is the websocket implementation of streamConn.
+type websocketConn struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	kind   tick.Kind
+	nextID int
+}
+
+// dialCombinedStreams opens a binance combined stream connection without any
+// stream subscribed yet.
+func dialCombinedStreams(
+	kind tick.Kind,
+	handler func(symbol string, event any),
+) (streamConn, <-chan error, error) {
+	return dialCombinedStreamsURL(combinedStreamURL, kind, handler)
+}
+
+func dialCombinedStreamsURL(
+	url string,
+	kind tick.Kind,
+	handler func(symbol string, event any),
+) (streamConn, <-chan error, error) {
+	if _, err := streamName(kind, ""); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &websocketConn{
+		conn: conn,
+		kind: kind,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.read(handler)
+	}()
+
+	return c, done, nil
+}
+
+// Subscribe will subscribe the symbols streams on the connection.
+func (c *websocketConn) Subscribe(symbols []string) error {
+	return c.send(subscribeMethod, symbols)
+}
+
+// Unsubscribe will unsubscribe the symbols streams from the connection.
+func (c *websocketConn) Unsubscribe(symbols []string) error {
+	return c.send(unsubscribeMethod, symbols)
+}
+
+// Close will close the connection.
+func (c *websocketConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *websocketConn) send(method string, symbols []string) error {
+	params := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		name, err := streamName(c.kind, s)
+		if err != nil {
+			return err
+		}
+		params = append(params, name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(streamRequest{
+		Method: method,
+		Params: params,
+		ID:     c.nextID,
+	})
+}
+
+func (c *websocketConn) read(handler func(symbol string, event any)) error {
+	for {
+		var msg streamMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+
+		// A rejected request ends the connection, the listeners will reconnect
+		if msg.Error != nil {
+			return fmt.Errorf("request rejected (%d): %s", msg.Error.Code, msg.Error.Msg)
+		}
+
+		// Skip the responses to the requests
+		if msg.Stream == "" {
+			continue
+		}
+
+		switch c.kind {
+		case tick.KindBook:
+			var event client.WsBookTickerEvent
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				continue
+			}
+			handler(event.Symbol, &event)
+		case tick.KindTrade:
+			var event client.WsAggTradeEvent
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				continue
+			}
+			handler(event.Symbol, &event)
+		}
+	}
+}
+
+// streamName returns the name of the binance stream of the symbol for the kind.
+func streamName(kind tick.Kind, symbol string) (string, error) {
+	switch kind {
+	case tick.KindBook:
+		return strings.ToLower(symbol) + "@bookTicker", nil
+	case tick.KindTrade:
+		return strings.ToLower(symbol) + "@aggTrade", nil
+	default:
+		return "", fmt.Errorf("%w: %q", tick.ErrUnknownKind, kind)
+	}
+}
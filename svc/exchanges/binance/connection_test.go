@@ -0,0 +1,108 @@
+//go:build unit
+// +build unit
+
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	client "github.com/adshao/go-binance/v2"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestConnectionSuite(t *testing.T) {
+	suite.Run(t, new(ConnectionSuite))
+}
+
+type ConnectionSuite struct {
+	suite.Suite
+
+	server   *httptest.Server
+	requests chan streamRequest
+}
+
+func (suite *ConnectionSuite) SetupTest() {
+	suite.requests = make(chan streamRequest, 10)
+
+	// Local stand-in of the binance combined streams: it acknowledges the
+	// requests and sends an event for each subscribed stream.
+	upgrader := websocket.Upgrader{}
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var req streamRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			suite.requests <- req
+
+			if err := conn.WriteJSON(map[string]any{"result": nil, "id": req.ID}); err != nil {
+				return
+			}
+			if req.Method != subscribeMethod {
+				continue
+			}
+			for _, stream := range req.Params {
+				symbol := strings.ToUpper(strings.Split(stream, "@")[0])
+				msg := `{"stream":"` + stream + `","data":{"u":1,"s":"` + symbol +
+					`","b":"100.1","B":"1","a":"100.2","A":"2"}}`
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func (suite *ConnectionSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ConnectionSuite) TestSubscribeAndUnsubscribe() {
+	events := make(chan *client.WsBookTickerEvent, 10)
+	conn, done, err := dialCombinedStreamsURL("ws"+strings.TrimPrefix(suite.server.URL, "http"), tick.KindBook,
+		func(symbol string, event any) {
+			suite.Require().Equal("BTCUSDT", symbol)
+			events <- event.(*client.WsBookTickerEvent)
+		})
+	suite.Require().NoError(err)
+
+	// Subscribe the stream on the live connection
+	suite.Require().NoError(conn.Subscribe([]string{"BTCUSDT"}))
+	req := <-suite.requests
+	suite.Require().Equal(subscribeMethod, req.Method)
+	suite.Require().Equal([]string{"btcusdt@bookTicker"}, req.Params)
+
+	select {
+	case event := <-events:
+		suite.Require().Equal("100.1", event.BestBidPrice)
+		suite.Require().Equal("100.2", event.BestAskPrice)
+	case <-time.After(time.Second):
+		suite.FailNow("event not received")
+	}
+
+	// Unsubscribe it without closing the connection
+	suite.Require().NoError(conn.Unsubscribe([]string{"BTCUSDT"}))
+	req = <-suite.requests
+	suite.Require().Equal(unsubscribeMethod, req.Method)
+	suite.Require().Equal([]string{"btcusdt@bookTicker"}, req.Params)
+
+	// Closing the connection ends the reading
+	suite.Require().NoError(conn.Close())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		suite.FailNow("connection not done")
+	}
+}
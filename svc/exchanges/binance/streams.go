@@ -0,0 +1,398 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+const (
+	// maxStreamsPerConnection is the maximum number of symbols served by a
+	// single combined stream connection (binance allows up to 1024).
+	maxStreamsPerConnection = 200
+	// defaultUpdateDelay is the time waited before updating the streams of a
+	// connection after a change, in order to group attachments and detachments
+	// as binance limits the number of requests per second on a connection.
+	defaultUpdateDelay = 500 * time.Millisecond
+	// subscriptionBufferSize is the number of events buffered for a
+	// subscription before they are dropped.
+	subscriptionBufferSize = 1000
+)
+
+// ErrStreamClosed is returned when a combined stream has been closed by binance.
+var ErrStreamClosed = errors.New("binance stream closed")
+
+// gapEvent is the event passed to a subscription handler instead of the events
+// dropped because the handler was too slow to process them.
+type gapEvent struct {
+	Start time.Time
+	End   time.Time
+}
+
+// subscription is the attachment of a listener to a symbol of a combined stream.
+// Its handler runs on its own goroutine so a slow handler does not stall the
+// other subscriptions of the connection.
+type subscription struct {
+	stream  *combinedStream
+	symbol  string
+	handler func(event any)
+
+	events    chan any
+	droppedAt time.Time
+	stop      chan struct{}
+	isStopped bool
+
+	connected   chan struct{}
+	isConnected bool
+	closed      chan error
+	isClosed    bool
+}
+
+// Connected returns a channel closed when the combined stream serving the
+// subscription is connected.
+func (s *subscription) Connected() <-chan struct{} {
+	return s.connected
+}
+
+// Closed returns a channel receiving the error that closed the combined stream
+// serving the subscription.
+func (s *subscription) Closed() <-chan error {
+	return s.closed
+}
+
+func (s *subscription) setConnected() {
+	if !s.isConnected {
+		s.isConnected = true
+		close(s.connected)
+	}
+}
+
+func (s *subscription) close(err error) {
+	if !s.isClosed {
+		s.isClosed = true
+		s.closed <- err
+	}
+}
+
+func (s *subscription) run() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case event := <-s.events:
+			s.handler(event)
+		}
+	}
+}
+
+// push queues the event without blocking. When the buffer is full, the event
+// is dropped and a gap event is queued as soon as there is room again.
+func (s *subscription) push(event any) {
+	if !s.droppedAt.IsZero() {
+		select {
+		case s.events <- gapEvent{Start: s.droppedAt, End: time.Now().UTC()}:
+			s.droppedAt = time.Time{}
+		default:
+			return
+		}
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.droppedAt = time.Now().UTC()
+	}
+}
+
+func (s *subscription) stopRunning() {
+	if !s.isStopped {
+		s.isStopped = true
+		close(s.stop)
+	}
+}
+
+// combinedStream is a connection serving several symbols of the same kind.
+type combinedStream struct {
+	kind          tick.Kind
+	subscriptions map[string][]*subscription
+	conn          streamConn
+	subscribed    map[string]bool
+	pending       bool
+	updating      bool
+}
+
+// streamManager shares combined stream connections between the listeners of
+// the worker: listeners attach to a symbol and the streams are subscribed and
+// unsubscribed on the live connections when the set of symbols changes.
+type streamManager struct {
+	mu          sync.Mutex
+	dial        dialFunc
+	updateDelay time.Duration
+	streams     []*combinedStream
+}
+
+func newStreamManager(dial dialFunc) *streamManager {
+	return &streamManager{
+		dial:        dial,
+		updateDelay: defaultUpdateDelay,
+	}
+}
+
+// Attach will attach the handler to the events of the symbol for the kind.
+func (m *streamManager) Attach(kind tick.Kind, symbol string, handler func(event any)) *subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stream := m.streamFor(kind, symbol)
+	sub := &subscription{
+		stream:    stream,
+		symbol:    symbol,
+		handler:   handler,
+		events:    make(chan any, subscriptionBufferSize),
+		stop:      make(chan struct{}),
+		connected: make(chan struct{}),
+		closed:    make(chan error, 1),
+	}
+	go sub.run()
+
+	// Reuse the stream if the symbol is already subscribed, otherwise subscribe it
+	stream.subscriptions[symbol] = append(stream.subscriptions[symbol], sub)
+	if stream.subscribed[symbol] {
+		sub.setConnected()
+	} else {
+		m.scheduleUpdate(stream)
+	}
+
+	return sub
+}
+
+// Detach will detach the subscription from its combined stream.
+func (m *streamManager) Detach(sub *subscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub.stopRunning()
+
+	stream := sub.stream
+	subs := stream.subscriptions[sub.symbol]
+	for i, s := range subs {
+		if s == sub {
+			subs = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	// Unsubscribe the symbol if nobody listens to it anymore
+	if len(subs) > 0 {
+		stream.subscriptions[sub.symbol] = subs
+	} else if _, ok := stream.subscriptions[sub.symbol]; ok {
+		delete(stream.subscriptions, sub.symbol)
+		m.scheduleUpdate(stream)
+	}
+}
+
+func (m *streamManager) streamFor(kind tick.Kind, symbol string) *combinedStream {
+	// Get the stream already serving the symbol
+	for _, s := range m.streams {
+		if _, ok := s.subscriptions[symbol]; ok && s.kind == kind {
+			return s
+		}
+	}
+
+	// Otherwise get a stream with room for the symbol
+	for _, s := range m.streams {
+		if s.kind == kind && len(s.subscriptions) < maxStreamsPerConnection {
+			return s
+		}
+	}
+
+	// Otherwise create a new one
+	s := &combinedStream{
+		kind:          kind,
+		subscriptions: make(map[string][]*subscription),
+		subscribed:    make(map[string]bool),
+	}
+	m.streams = append(m.streams, s)
+	return s
+}
+
+func (m *streamManager) scheduleUpdate(stream *combinedStream) {
+	if stream.pending {
+		return
+	}
+
+	stream.pending = true
+	time.AfterFunc(m.updateDelay, func() {
+		m.update(stream)
+	})
+}
+
+// update subscribes and unsubscribes the streams of the connection to match the
+// attached symbols, without interrupting the symbols that are still attached.
+// The network requests are done without holding the lock, so that the events
+// of the other streams are still dispatched in the meantime.
+func (m *streamManager) update(stream *combinedStream) {
+	m.mu.Lock()
+
+	// Try again later if the previous update is not done yet
+	if stream.updating {
+		m.mu.Unlock()
+		time.AfterFunc(m.updateDelay, func() {
+			m.update(stream)
+		})
+		return
+	}
+	stream.pending = false
+
+	// Close the connection if nobody listens to it anymore
+	if len(stream.subscriptions) == 0 {
+		conn := stream.conn
+		stream.conn = nil
+		m.removeStream(stream)
+		m.mu.Unlock()
+
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return
+	}
+
+	// Get the changes to apply to the connection
+	conn := stream.conn
+	toUnsubscribe, toSubscribe := stream.changes()
+	stream.updating = true
+	m.mu.Unlock()
+
+	// Open the connection if it is not already, then update its streams
+	var done <-chan error
+	var err error
+	dialed := conn == nil
+	if dialed {
+		conn, done, err = m.dial(stream.kind, func(symbol string, event any) {
+			m.dispatch(stream, symbol, event)
+		})
+	}
+	if err == nil && len(toUnsubscribe) > 0 {
+		if err = conn.Unsubscribe(toUnsubscribe); err != nil {
+			err = fmt.Errorf("%w: %w", ErrStreamClosed, err)
+		}
+	}
+	if err == nil && len(toSubscribe) > 0 {
+		if err = conn.Subscribe(toSubscribe); err != nil {
+			err = fmt.Errorf("%w: %w", ErrStreamClosed, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stream.updating = false
+
+	// Keep the new connection, or stop if the current one has been closed in
+	// the meantime as its subscriptions are already closed
+	switch {
+	case dialed && conn != nil:
+		stream.conn = conn
+		stream.subscribed = make(map[string]bool)
+		go m.watch(stream, conn, done)
+	case !dialed && stream.conn != conn:
+		return
+	}
+	if err != nil {
+		m.closeStream(stream, err)
+		return
+	}
+
+	// Record the changes and notify the subscriptions of the subscribed symbols
+	for _, symbol := range toUnsubscribe {
+		delete(stream.subscribed, symbol)
+	}
+	for _, symbol := range toSubscribe {
+		stream.subscribed[symbol] = true
+	}
+	for symbol, subs := range stream.subscriptions {
+		if !stream.subscribed[symbol] {
+			continue
+		}
+		for _, sub := range subs {
+			sub.setConnected()
+		}
+	}
+}
+
+// changes returns the symbols to unsubscribe from and to subscribe to on the
+// connection to match the attached symbols.
+func (s *combinedStream) changes() (toUnsubscribe, toSubscribe []string) {
+	toUnsubscribe = make([]string, 0)
+	for symbol := range s.subscribed {
+		if _, ok := s.subscriptions[symbol]; !ok {
+			toUnsubscribe = append(toUnsubscribe, symbol)
+		}
+	}
+	toSubscribe = make([]string, 0)
+	for symbol := range s.subscriptions {
+		if !s.subscribed[symbol] {
+			toSubscribe = append(toSubscribe, symbol)
+		}
+	}
+	sort.Strings(toUnsubscribe)
+	sort.Strings(toSubscribe)
+
+	return toUnsubscribe, toSubscribe
+}
+
+func (m *streamManager) watch(stream *combinedStream, conn streamConn, done <-chan error) {
+	err := <-done
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The connection has been closed by the manager
+	if stream.conn != conn {
+		return
+	}
+
+	// Otherwise the connection has been closed by binance
+	if err != nil {
+		m.closeStream(stream, fmt.Errorf("%w: %w", ErrStreamClosed, err))
+	} else {
+		m.closeStream(stream, ErrStreamClosed)
+	}
+}
+
+func (m *streamManager) dispatch(stream *combinedStream, symbol string, event any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sub := range stream.subscriptions[symbol] {
+		sub.push(event)
+	}
+}
+
+// closeStream closes all the subscriptions of the stream with the error and
+// removes it: listeners will attach again to a new stream.
+func (m *streamManager) closeStream(stream *combinedStream, err error) {
+	for _, subs := range stream.subscriptions {
+		for _, sub := range subs {
+			sub.close(err)
+		}
+	}
+	stream.subscriptions = make(map[string][]*subscription)
+	stream.subscribed = make(map[string]bool)
+	if stream.conn != nil {
+		_ = stream.conn.Close()
+		stream.conn = nil
+	}
+	m.removeStream(stream)
+}
+
+func (m *streamManager) removeStream(stream *combinedStream) {
+	for i, s := range m.streams {
+		if s == stream {
+			m.streams = append(m.streams[:i], m.streams[i+1:]...)
+			return
+		}
+	}
+}
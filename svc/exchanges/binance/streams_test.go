@@ -0,0 +1,344 @@
+//go:build unit
+// +build unit
+
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	client "github.com/adshao/go-binance/v2"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStreamsSuite(t *testing.T) {
+	suite.Run(t, new(StreamsSuite))
+}
+
+// fakeConn is a combined stream connection recording the requests.
+type fakeConn struct {
+	mu           sync.Mutex
+	kind         tick.Kind
+	handler      func(symbol string, event any)
+	done         chan error
+	subscribed   []string
+	unsubscribed []string
+	closed       bool
+	// pause, if set, makes the subscriptions wait until it is closed.
+	pause chan struct{}
+}
+
+func (c *fakeConn) Subscribe(symbols []string) error {
+	c.mu.Lock()
+	pause := c.pause
+	c.mu.Unlock()
+	if pause != nil {
+		<-pause
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribed = append(c.subscribed, symbols...)
+	return nil
+}
+
+func (c *fakeConn) setPause(pause chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pause = pause
+}
+
+func (c *fakeConn) Unsubscribe(symbols []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsubscribed = append(c.unsubscribed, symbols...)
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) state() (subscribed, unsubscribed []string, closed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.subscribed...), append([]string(nil), c.unsubscribed...), c.closed
+}
+
+type StreamsSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	mu      sync.Mutex
+	conns   []*fakeConn
+	manager *streamManager
+}
+
+func (suite *StreamsSuite) SetupTest() {
+	suite.conns = nil
+	suite.manager = newStreamManager(suite.dial)
+	suite.manager.updateDelay = 10 * time.Millisecond
+}
+
+func (suite *StreamsSuite) dial(
+	kind tick.Kind,
+	handler func(symbol string, event any),
+) (streamConn, <-chan error, error) {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+
+	c := &fakeConn{
+		kind:    kind,
+		handler: handler,
+		done:    make(chan error, 1),
+	}
+	suite.conns = append(suite.conns, c)
+	return c, c.done, nil
+}
+
+func (suite *StreamsSuite) connections() []*fakeConn {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	return append([]*fakeConn(nil), suite.conns...)
+}
+
+func (suite *StreamsSuite) waitConnected(subs ...*subscription) {
+	for _, sub := range subs {
+		select {
+		case <-sub.Connected():
+		case <-time.After(time.Second):
+			suite.FailNow("subscription not connected")
+		}
+	}
+}
+
+func (suite *StreamsSuite) TestSharedConnection() {
+	btc, eth := make(chan any, 1), make(chan any, 1)
+	btcSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) { btc <- event })
+	ethSub := suite.manager.Attach(tick.KindBook, "ETHUSDT", func(event any) { eth <- event })
+	suite.waitConnected(btcSub, ethSub)
+
+	// Both symbols are served by a single connection
+	conns := suite.connections()
+	suite.Require().Len(conns, 1)
+	suite.Require().Equal(tick.KindBook, conns[0].kind)
+	subscribed, _, _ := conns[0].state()
+	suite.Require().Equal([]string{"BTCUSDT", "ETHUSDT"}, subscribed)
+
+	// Events are dispatched to the corresponding subscription
+	conns[0].handler("BTCUSDT", "btc-event")
+	conns[0].handler("ETHUSDT", "eth-event")
+	suite.Require().Equal("btc-event", <-btc)
+	suite.Require().Equal("eth-event", <-eth)
+
+	// Another listener on a served symbol reuses the connection
+	otherSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {})
+	suite.waitConnected(otherSub)
+	suite.Require().Len(suite.connections(), 1)
+}
+
+func (suite *StreamsSuite) TestKindsOnSeparateConnections() {
+	bookSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {})
+	tradeSub := suite.manager.Attach(tick.KindTrade, "BTCUSDT", func(event any) {})
+	suite.waitConnected(bookSub, tradeSub)
+
+	conns := suite.connections()
+	suite.Require().Len(conns, 2)
+	suite.Require().NotEqual(conns[0].kind, conns[1].kind)
+}
+
+func (suite *StreamsSuite) TestAttachAndDetachOnLiveConnection() {
+	btcSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {})
+	suite.waitConnected(btcSub)
+
+	// Attaching a symbol subscribes it on the same connection
+	ethSub := suite.manager.Attach(tick.KindBook, "ETHUSDT", func(event any) {})
+	suite.waitConnected(ethSub)
+	conns := suite.connections()
+	suite.Require().Len(conns, 1)
+	subscribed, _, _ := conns[0].state()
+	suite.Require().Equal([]string{"BTCUSDT", "ETHUSDT"}, subscribed)
+
+	// Detaching a symbol unsubscribes it without interrupting the others
+	suite.manager.Detach(ethSub)
+	suite.Require().Eventually(func() bool {
+		_, unsubscribed, _ := conns[0].state()
+		return len(unsubscribed) == 1
+	}, time.Second, time.Millisecond)
+	_, unsubscribed, closed := conns[0].state()
+	suite.Require().Equal([]string{"ETHUSDT"}, unsubscribed)
+	suite.Require().False(closed)
+	suite.Require().Len(suite.connections(), 1)
+	select {
+	case err := <-btcSub.Closed():
+		suite.FailNow("subscription closed", err)
+	default:
+	}
+
+	// Detaching the last symbol closes the connection
+	suite.manager.Detach(btcSub)
+	suite.Require().Eventually(func() bool {
+		_, _, closed := conns[0].state()
+		return closed
+	}, time.Second, time.Millisecond)
+	suite.Require().Len(suite.connections(), 1)
+}
+
+func (suite *StreamsSuite) TestDispatchDuringUpdate() {
+	btc := make(chan any, 1)
+	btcSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) { btc <- event })
+	suite.waitConnected(btcSub)
+
+	// Attach a symbol while the subscription request is slow
+	conns := suite.connections()
+	pause := make(chan struct{})
+	conns[0].setPause(pause)
+	ethSub := suite.manager.Attach(tick.KindBook, "ETHUSDT", func(event any) {})
+	time.Sleep(5 * suite.manager.updateDelay)
+
+	// Events of the subscribed symbols are still dispatched in the meantime
+	go conns[0].handler("BTCUSDT", "btc-event")
+	select {
+	case event := <-btc:
+		suite.Require().Equal("btc-event", event)
+	case <-time.After(time.Second):
+		suite.FailNow("event not dispatched during the update")
+	}
+
+	close(pause)
+	suite.waitConnected(ethSub)
+	subscribed, _, _ := conns[0].state()
+	suite.Require().Equal([]string{"BTCUSDT", "ETHUSDT"}, subscribed)
+}
+
+func (suite *StreamsSuite) TestConnectionClosed() {
+	sub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {})
+	suite.waitConnected(sub)
+
+	// Close the connection from binance side
+	conns := suite.connections()
+	conns[0].done <- errors.New("connection reset")
+
+	select {
+	case err := <-sub.Closed():
+		suite.Require().ErrorIs(err, ErrStreamClosed)
+		suite.Require().ErrorContains(err, "connection reset")
+	case <-time.After(time.Second):
+		suite.FailNow("subscription not closed")
+	}
+
+	// Attaching again creates a new connection
+	sub = suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {})
+	suite.waitConnected(sub)
+	suite.Require().Len(suite.connections(), 2)
+}
+
+func (suite *StreamsSuite) TestMaxStreamsPerConnection() {
+	subs := make([]*subscription, 0, maxStreamsPerConnection+1)
+	for i := 0; i <= maxStreamsPerConnection; i++ {
+		sub := suite.manager.Attach(tick.KindBook, fmt.Sprintf("SYM%dUSDT", i), func(event any) {})
+		subs = append(subs, sub)
+	}
+	suite.waitConnected(subs...)
+
+	conns := suite.connections()
+	suite.Require().Len(conns, 2)
+	first, _, _ := conns[0].state()
+	second, _, _ := conns[1].state()
+	suite.Require().ElementsMatch([]int{maxStreamsPerConnection, 1}, []int{len(first), len(second)})
+}
+
+func (suite *StreamsSuite) TestSlowSubscription() {
+	entered, block := make(chan struct{}, 1), make(chan struct{})
+	slow := make(chan any, subscriptionBufferSize+2)
+	fast := make(chan any, 1)
+	slowSub := suite.manager.Attach(tick.KindBook, "BTCUSDT", func(event any) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-block
+		slow <- event
+	})
+	fastSub := suite.manager.Attach(tick.KindBook, "ETHUSDT", func(event any) { fast <- event })
+	suite.waitConnected(slowSub, fastSub)
+	conns := suite.connections()
+
+	// Block the slow subscription handler and overflow its buffer
+	conns[0].handler("BTCUSDT", 0)
+	<-entered
+	for i := 1; i <= subscriptionBufferSize+1; i++ {
+		conns[0].handler("BTCUSDT", i)
+	}
+
+	// The other subscription is not stalled
+	conns[0].handler("ETHUSDT", "eth-event")
+	select {
+	case event := <-fast:
+		suite.Require().Equal("eth-event", event)
+	case <-time.After(time.Second):
+		suite.FailNow("subscription stalled by another one")
+	}
+
+	// The slow subscription receives a gap for the dropped events
+	close(block)
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		suite.Require().Equal(i, <-slow)
+	}
+	conns[0].handler("BTCUSDT", "after")
+	gap, ok := (<-slow).(gapEvent)
+	suite.Require().True(ok)
+	suite.Require().False(gap.End.Before(gap.Start))
+	suite.Require().Equal("after", <-slow)
+}
+
+func (suite *StreamsSuite) TestListenerStopsWhenParentNotRunning() {
+	// The sentry is gone
+	mock := temporal.NewMockClient(gomock.NewController(suite.T()))
+	mock.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		Return(serviceerror.NewNotFound("workflow execution already completed"))
+	mock.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	// Send a tick once the listener is connected
+	go func() {
+		suite.Require().Eventually(func() bool {
+			return len(suite.connections()) == 1
+		}, time.Second, time.Millisecond)
+		suite.connections()[0].handler("BTCUSDT", &client.WsBookTickerEvent{
+			BestBidPrice: "100", BestBidQty: "1", BestAskPrice: "101", BestAskQty: "1",
+		})
+	}()
+
+	acts := &Activities{temporal: mock, streams: suite.manager}
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err := env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+	})
+	suite.Require().ErrorContains(err, signaling.ErrParentNotRunning.Error())
+
+	// The symbol is not listened anymore
+	suite.Require().Eventually(func() bool {
+		_, _, closed := suite.connections()[0].state()
+		return closed
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,190 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets"
+	"github.com/gorilla/websocket"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// pingInterval is the interval between two pings, as Bybit closes
+// connections without ping after 30 seconds.
+const pingInterval = 20 * time.Second
+
+// Activities is the struct that will handle the exchange.
+type Activities struct {
+	temporal     temporalclient.Client
+	websocketURL string
+	pingInterval time.Duration
+}
+
+// New will create a new bybit exchange.
+func New(temporal temporalclient.Client, websocketURL string) (*Activities, error) {
+	if websocketURL == "" {
+		return nil, errors.New("websocket URL cannot be empty")
+	}
+
+	return &Activities{
+		temporal:     temporal,
+		websocketURL: websocketURL,
+		pingInterval: pingInterval,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return false as bybit pairs are defined on the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return nil, false
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will listen to the symbol activity.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	bybitSymbol, err := toBybitSymbol(params.Symbol)
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Start heartbeat on activity
+	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
+
+	// Listen to the feed, reconnecting when it is interrupted
+	err = websockets.ListenWithReconnection(ctx, backoff.NewExponentialBackOff(),
+		func(ctx context.Context, connected func()) error {
+			return a.listen(ctx, bybitSymbol, params, connected)
+		},
+		func(start, end time.Time) {
+			_ = a.signalNewTick(ctx, tick.FromGap(ExchangeName, params.Symbol, start, end), params)
+		})
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf("bybit listener stopped: %w", err)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+func (a *Activities) listen(
+	ctx context.Context,
+	bybitSymbol string,
+	params exchanges.ListenSymbolParams,
+	connected func(),
+) error {
+	// Connect to the websocket and subscribe to the topic corresponding to the kind
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, a.websocketURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	topic := orderbookTopic
+	if params.Kind.OrDefault() == tick.KindTrade {
+		topic = tradeTopic
+	}
+	if err := conn.WriteJSON(operationMessage{
+		Operation: subscribeOperation,
+		Args:      []string{fmt.Sprintf("%s.%s", topic, bybitSymbol)},
+	}); err != nil {
+		return err
+	}
+	connected()
+
+	// Close the connection when the session is done to stop reading
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	// Keep the connection alive with pings
+	ping, _ := json.Marshal(operationMessage{Operation: pingOperation})
+	websockets.KeepAlive(ctx, conn, a.pingInterval, ping)
+
+	// Read messages until the connection is closed
+	return a.readMessages(ctx, conn, params)
+}
+
+func (a *Activities) signalNewTick(ctx context.Context, t tick.Tick, params exchanges.ListenSymbolParams) error {
+	return signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t)
+}
+
+func (a *Activities) readMessages(
+	ctx context.Context,
+	conn *websocket.Conn,
+	params exchanges.ListenSymbolParams,
+) error {
+	var last tick.Tick
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+
+		// Check operation responses (subscription, pong, etc)
+		if msg.Success != nil {
+			if !*msg.Success {
+				return backoff.Permanent(errors.New(msg.RetMsg))
+			}
+			continue
+		}
+
+		// Convert topic data to ticks
+		var ticks []tick.Tick
+		var err error
+		switch {
+		case strings.HasPrefix(msg.Topic, orderbookTopic+"."):
+			var t tick.Tick
+			t, err = toBookTick(params.Symbol, msg)
+			ticks = []tick.Tick{t}
+		case strings.HasPrefix(msg.Topic, tradeTopic+"."):
+			ticks, err = toTradeTicks(params.Symbol, msg)
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		// Send them to main workflow through Signal
+		for _, t := range ticks {
+			// Skip if same prices and quantities as last book tick
+			if t.Kind == tick.KindBook && t.Exact.Equal(last.Exact) {
+				continue
+			}
+			last = t
+
+			if err = a.signalNewTick(ctx, t, params); err != nil {
+				return err
+			}
+		}
+	}
+}
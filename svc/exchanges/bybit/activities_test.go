@@ -0,0 +1,179 @@
+//go:build unit
+// +build unit
+
+package bybit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets/websocketstest"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+// closingMessage is sent by the test server to end the listener.
+const closingMessage = `{"success":false,"ret_msg":"Test server closed","op":"subscribe"}`
+
+func TestBybitSuite(t *testing.T) {
+	suite.Run(t, new(BybitSuite))
+}
+
+type BybitSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	temporal *temporal.MockClient
+	server   *websocketstest.Server
+}
+
+func (suite *BybitSuite) SetupTest() {
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	suite.server = websocketstest.NewServer(websocketstest.Options{
+		ClosingMessage: closingMessage,
+		Pong:           `{"success":true,"ret_msg":"pong","op":"ping"}`,
+	})
+}
+
+func (suite *BybitSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// subscriptions returns the subscriptions received by the test server.
+func (suite *BybitSuite) subscriptions() []operationMessage {
+	subs := make([]operationMessage, 0)
+	for _, raw := range suite.server.Subscriptions() {
+		var sub operationMessage
+		suite.Require().NoError(json.Unmarshal([]byte(raw), &sub))
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (suite *BybitSuite) listen(kind tick.Kind) ([]tick.Tick, error) {
+	acts, err := New(suite.temporal, suite.server.URL)
+	suite.Require().NoError(err)
+	acts.pingInterval = 10 * time.Millisecond
+
+	ticks := make([]tick.Tick, 0)
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, arg any) error {
+			ticks = append(ticks, arg.(tick.Tick))
+			return nil
+		}).AnyTimes()
+
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+		Kind:             kind,
+	})
+	return ticks, err
+}
+
+func (suite *BybitSuite) TestBookTicks() {
+	suite.server.SetMessages(
+		`{"success":true,"ret_msg":"","op":"subscribe","conn_id":"id"}`,
+		`{"topic":"orderbook.1.BTCUSDT","type":"snapshot","ts":1704067201000,`+
+			`"data":{"s":"BTCUSDT","b":[["100","1.5"]],"a":[["101","2"]],"u":1,"seq":1}}`,
+		`{"topic":"orderbook.1.BTCUSDT","type":"snapshot","ts":1704067201500,`+
+			`"data":{"s":"BTCUSDT","b":[["100","1.5"]],"a":[["101","2"]],"u":2,"seq":2}}`,
+		`{"topic":"orderbook.1.BTCUSDT","type":"snapshot","ts":1704067202000,`+
+			`"data":{"s":"BTCUSDT","b":[["100","1.4"]],"a":[["101","2"]],"u":3,"seq":3}}`,
+		`{"topic":"orderbook.1.BTCUSDT","type":"snapshot","ts":1704067203000,`+
+			`"data":{"s":"BTCUSDT","b":[["100.5","1"]],"a":[["101","2"]],"u":4,"seq":4}}`,
+	)
+
+	ticks, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "bybit listener stopped")
+
+	// Subscription then ping
+	subs := suite.subscriptions()
+	suite.Require().Len(subs, 1)
+	suite.Require().Equal(subscribeOperation, subs[0].Operation)
+	suite.Require().Equal([]string{"orderbook.1.BTCUSDT"}, subs[0].Args)
+	pings := suite.server.Pings()
+	suite.Require().Len(pings, 1)
+	var ping operationMessage
+	suite.Require().NoError(json.Unmarshal([]byte(pings[0]), &ping))
+	suite.Require().Equal(pingOperation, ping.Operation)
+
+	// Second message is skipped as it has the same bid/ask and quantities
+	suite.Require().Len(ticks, 3)
+	suite.Require().Equal(ExchangeName, ticks[0].Exchange)
+	suite.Require().Equal("BTC-USDT", ticks[0].Pair)
+	suite.Require().Equal(100.0, ticks[0].Bid)
+	suite.Require().Equal(1.5, ticks[0].BidQty)
+	suite.Require().Equal(101.0, ticks[0].Ask)
+	suite.Require().Equal(int64(1704067201000), ticks[0].Time.UnixMilli())
+	suite.Require().Equal(100.0, ticks[1].Bid)
+	suite.Require().Equal(1.4, ticks[1].BidQty)
+	suite.Require().Equal(100.5, ticks[2].Bid)
+}
+
+func (suite *BybitSuite) TestTradeTicks() {
+	suite.server.SetMessages(
+		`{"topic":"publicTrade.BTCUSDT","type":"snapshot","ts":1704067203600,"data":[` +
+			`{"T":1704067203500,"s":"BTCUSDT","S":"Sell","v":"0.2","p":"100.7","i":"trade-1"},` +
+			`{"T":1704067203600,"s":"BTCUSDT","S":"Buy","v":"0.1","p":"100.8","i":"trade-2"}]}`,
+	)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "bybit listener stopped")
+
+	suite.Require().Equal([]string{"publicTrade.BTCUSDT"}, suite.subscriptions()[0].Args)
+	suite.Require().Len(ticks, 2)
+	suite.Require().Equal(tick.KindTrade, ticks[0].Kind)
+	suite.Require().Equal(100.7, ticks[0].Price)
+	suite.Require().Equal(0.2, ticks[0].Qty)
+	suite.Require().Equal(tick.SideSell, ticks[0].Side)
+	suite.Require().Equal(tick.SideBuy, ticks[1].Side)
+	suite.Require().Equal("trade-1", ticks[0].TradeID)
+	suite.Require().Equal("trade-2", ticks[1].TradeID)
+}
+
+func (suite *BybitSuite) TestSubscriptionError() {
+	suite.server.SetMessages(
+		`{"success":false,"ret_msg":"Invalid symbol :[orderbook.1.BTCXXX]","op":"subscribe"}`,
+	)
+
+	_, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "Invalid symbol")
+}
+
+func (suite *BybitSuite) TestReconnection() {
+	suite.server.SetMessages(
+		`{"topic":"publicTrade.BTCUSDT","type":"snapshot","ts":1704067203600,"data":[` +
+			`{"T":1704067203500,"s":"BTCUSDT","S":"Sell","v":"0.2","p":"100.7"},` +
+			`{"T":1704067203600,"s":"BTCUSDT","S":"Buy","v":"0.1","p":"100.8"}]}`,
+	)
+	suite.server.SetDrops(1)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "bybit listener stopped")
+	// Subscriptions of both connections and the ping of the last one
+	suite.Require().Len(suite.subscriptions(), 2)
+	suite.Require().Len(suite.server.Pings(), 1)
+
+	// Ticks from both connections are separated by a gap tick
+	suite.Require().Len(ticks, 5)
+	for i, t := range ticks {
+		if i == 2 {
+			suite.Require().True(t.IsGap())
+			suite.Require().Equal(tick.KindGap, t.Kind)
+			suite.Require().Equal("BTC-USDT", t.Pair)
+			suite.Require().False(t.Time.Before(t.GapStart))
+			continue
+		}
+		suite.Require().Equal(tick.KindTrade, t.Kind)
+	}
+}
@@ -0,0 +1,6 @@
+package bybit
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "bybit"
+)
@@ -0,0 +1,131 @@
+package bybit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// orderbookTopic is the topic of the best bid/ask of a symbol (level 1 order book).
+	orderbookTopic = "orderbook.1"
+	// tradeTopic is the topic of the public trades of a symbol.
+	tradeTopic = "publicTrade"
+
+	subscribeOperation = "subscribe"
+	pingOperation      = "ping"
+)
+
+var (
+	// errInvalidMessage is the error when a topic message has not the expected format.
+	errInvalidMessage = errors.New("invalid bybit message")
+)
+
+// operationMessage is the message sent to operate on the connection (i.e. subscribe or ping).
+type operationMessage struct {
+	Operation string   `json:"op"`
+	Args      []string `json:"args,omitempty"`
+}
+
+// message is a message received from the websocket, either an operation
+// response or a topic data message.
+type message struct {
+	Operation string `json:"op"`
+	Success   *bool  `json:"success"`
+	RetMsg    string `json:"ret_msg"`
+
+	Topic string          `json:"topic"`
+	TS    int64           `json:"ts"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// orderbookData is the data of the level 1 order book topic. Levels are
+// sent as [[price, size]].
+type orderbookData struct {
+	Bids [][]string `json:"b"`
+	Asks [][]string `json:"a"`
+}
+
+// tradeData is the data of a public trade.
+type tradeData struct {
+	ID    string `json:"i"`
+	Time  int64  `json:"T"`
+	Side  string `json:"S"`
+	Price string `json:"p"`
+	Qty   string `json:"v"`
+}
+
+func toBookTick(symbol string, msg message) (tick.Tick, error) {
+	var data orderbookData
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return tick.Tick{}, err
+	}
+	if len(data.Bids) == 0 || len(data.Asks) == 0 || len(data.Bids[0]) < 2 || len(data.Asks[0]) < 2 {
+		return tick.Tick{}, fmt.Errorf("%w: incomplete order book", errInvalidMessage)
+	}
+
+	bid, err := decimal.NewFromString(data.Bids[0][0])
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	bidQty, err := decimal.NewFromString(data.Bids[0][1])
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	ask, err := decimal.NewFromString(data.Asks[0][0])
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	askQty, err := decimal.NewFromString(data.Asks[0][1])
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	t := time.UnixMilli(msg.TS).UTC()
+	return tick.FromExactBook(ExchangeName, symbol, t, bid, bidQty, ask, askQty), nil
+}
+
+func toTradeTicks(symbol string, msg message) ([]tick.Tick, error) {
+	var trades []tradeData
+	if err := json.Unmarshal(msg.Data, &trades); err != nil {
+		return nil, err
+	}
+
+	ticks := make([]tick.Tick, 0, len(trades))
+	for _, trade := range trades {
+		price, err := decimal.NewFromString(trade.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		qty, err := decimal.NewFromString(trade.Qty)
+		if err != nil {
+			return nil, err
+		}
+
+		// The side of the trade is the side of the taker
+		side := tick.SideBuy
+		if trade.Side == "Sell" {
+			side = tick.SideSell
+		}
+
+		t := tick.FromExactTrade(ExchangeName, symbol, time.UnixMilli(trade.Time).UTC(), price, qty, side)
+		t.TradeID = trade.ID
+		ticks = append(ticks, t)
+	}
+
+	return ticks, nil
+}
+
+func toBybitSymbol(symbol string) (string, error) {
+	base, quote, err := pair.ParsePair(symbol)
+	return fmt.Sprintf("%s%s", base, quote), err
+}
@@ -0,0 +1,169 @@
+package coinbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets"
+	"github.com/gorilla/websocket"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Activities is the struct that will handle the exchange.
+type Activities struct {
+	temporal     temporalclient.Client
+	websocketURL string
+}
+
+// New will create a new coinbase exchange.
+func New(temporal temporalclient.Client, websocketURL string) (*Activities, error) {
+	if websocketURL == "" {
+		return nil, errors.New("websocket URL cannot be empty")
+	}
+
+	return &Activities{
+		temporal:     temporal,
+		websocketURL: websocketURL,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return false as coinbase pairs are defined on the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return nil, false
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will listen to the symbol activity.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	productID, err := toCoinbaseProductID(params.Symbol)
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Start heartbeat on activity
+	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
+
+	// Listen to the feed, reconnecting when it is interrupted
+	err = websockets.ListenWithReconnection(ctx, backoff.NewExponentialBackOff(),
+		func(ctx context.Context, connected func()) error {
+			return a.listen(ctx, productID, params, connected)
+		},
+		func(start, end time.Time) {
+			_ = a.signalNewTick(ctx, tick.FromGap(ExchangeName, params.Symbol, start, end), params)
+		})
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf("coinbase listener stopped: %w", err)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+func (a *Activities) listen(
+	ctx context.Context,
+	productID string,
+	params exchanges.ListenSymbolParams,
+	connected func(),
+) error {
+	// Connect to the websocket feed and subscribe to the ticker channel
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, a.websocketURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(subscribeMessage{
+		Type:       subscribeMessageType,
+		ProductIDs: []string{productID},
+		Channels:   []string{tickerChannel},
+	}); err != nil {
+		return err
+	}
+	connected()
+
+	// Close the connection when the session is done to stop reading
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	// Read messages until the connection is closed
+	return a.readMessages(ctx, conn, params)
+}
+
+func (a *Activities) signalNewTick(ctx context.Context, t tick.Tick, params exchanges.ListenSymbolParams) error {
+	return signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t)
+}
+
+func (a *Activities) readMessages(
+	ctx context.Context,
+	conn *websocket.Conn,
+	params exchanges.ListenSymbolParams,
+) error {
+	var last message
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case errorMessageType:
+			return backoff.Permanent(fmt.Errorf("%s: %s", msg.Message, msg.Reason))
+		case tickerMessageType:
+		default:
+			continue
+		}
+
+		// Convert to tick
+		var t tick.Tick
+		var err error
+		if params.Kind.OrDefault() == tick.KindTrade {
+			t, err = toTradeTick(params.Symbol, msg)
+		} else {
+			// Skip if same prices and quantities as last tick
+			if msg.BestBid == last.BestBid && msg.BestBidSize == last.BestBidSize &&
+				msg.BestAsk == last.BestAsk && msg.BestAskSize == last.BestAskSize {
+				continue
+			}
+			last = msg
+
+			t, err = toBookTick(params.Symbol, msg)
+		}
+		if err != nil {
+			continue
+		}
+
+		// Send it to main workflow through Signal
+		if err = a.signalNewTick(ctx, t, params); err != nil {
+			return err
+		}
+	}
+}
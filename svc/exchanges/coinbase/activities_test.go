@@ -0,0 +1,194 @@
+//go:build unit
+// +build unit
+
+package coinbase
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets/websocketstest"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+// closingMessage is sent by the test server to end the listener.
+const closingMessage = `{"type":"error","message":"Closing","reason":"test server closed"}`
+
+func TestCoinbaseSuite(t *testing.T) {
+	suite.Run(t, new(CoinbaseSuite))
+}
+
+type CoinbaseSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	temporal *temporal.MockClient
+	server   *websocketstest.Server
+}
+
+func (suite *CoinbaseSuite) SetupTest() {
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	suite.server = websocketstest.NewServer(websocketstest.Options{
+		ClosingMessage: closingMessage,
+	})
+}
+
+func (suite *CoinbaseSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// subscriptions returns the subscriptions received by the test server.
+func (suite *CoinbaseSuite) subscriptions() []subscribeMessage {
+	subs := make([]subscribeMessage, 0)
+	for _, raw := range suite.server.Subscriptions() {
+		var sub subscribeMessage
+		suite.Require().NoError(json.Unmarshal([]byte(raw), &sub))
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (suite *CoinbaseSuite) listen(kind tick.Kind) ([]tick.Tick, error) {
+	acts, err := New(suite.temporal, suite.server.URL)
+	suite.Require().NoError(err)
+
+	ticks := make([]tick.Tick, 0)
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, arg any) error {
+			ticks = append(ticks, arg.(tick.Tick))
+			return nil
+		}).AnyTimes()
+
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USD",
+		Kind:             kind,
+	})
+	return ticks, err
+}
+
+func (suite *CoinbaseSuite) TestBookTicks() {
+	suite.server.SetMessages(
+		`{"type":"subscriptions","channels":[{"name":"ticker","product_ids":["BTC-USD"]}]}`,
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.5","best_bid":"100","best_bid_size":"1.5",`+
+			`"best_ask":"101","best_ask_size":"2","side":"buy","last_size":"0.1","time":"2024-01-01T00:00:01Z"}`,
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.6","best_bid":"100","best_bid_size":"1.5",`+
+			`"best_ask":"101","best_ask_size":"2","side":"buy","last_size":"0.1","time":"2024-01-01T00:00:01.5Z"}`,
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.6","best_bid":"100","best_bid_size":"1.4",`+
+			`"best_ask":"101","best_ask_size":"2","side":"buy","last_size":"0.1","time":"2024-01-01T00:00:02Z"}`,
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.7","best_bid":"100.5","best_bid_size":"1",`+
+			`"best_ask":"101","best_ask_size":"2","side":"sell","last_size":"0.2","time":"2024-01-01T00:00:03Z"}`,
+	)
+
+	ticks, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "coinbase listener stopped")
+
+	suite.Require().Len(suite.subscriptions(), 1)
+	suite.Require().Equal([]string{"BTC-USD"}, suite.subscriptions()[0].ProductIDs)
+	suite.Require().Equal([]string{tickerChannel}, suite.subscriptions()[0].Channels)
+
+	// Second ticker is skipped as it has the same bid/ask and quantities
+	suite.Require().Len(ticks, 3)
+	suite.Require().Equal(ExchangeName, ticks[0].Exchange)
+	suite.Require().Equal("BTC-USD", ticks[0].Pair)
+	suite.Require().Equal(tick.KindBook, ticks[0].Kind)
+	suite.Require().Equal(100.0, ticks[0].Bid)
+	suite.Require().Equal(101.0, ticks[0].Ask)
+	suite.Require().Equal(100.0, ticks[1].Bid)
+	suite.Require().Equal(1.4, ticks[1].BidQty)
+	suite.Require().Equal(100.5, ticks[2].Bid)
+}
+
+func (suite *CoinbaseSuite) TestTradeTicks() {
+	suite.server.SetMessages(
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.7","best_bid":"100.5","best_bid_size":"1",` +
+			`"best_ask":"101","best_ask_size":"2","side":"sell","last_size":"0.2","trade_id":42,"time":"2024-01-01T00:00:03Z"}`,
+	)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "coinbase listener stopped")
+
+	suite.Require().Len(ticks, 1)
+	suite.Require().Equal(tick.KindTrade, ticks[0].Kind)
+	suite.Require().Equal(100.7, ticks[0].Price)
+	suite.Require().Equal(0.2, ticks[0].Qty)
+	suite.Require().Equal(tick.SideSell, ticks[0].Side)
+	suite.Require().Equal("42", ticks[0].TradeID)
+}
+
+func (suite *CoinbaseSuite) TestErrorMessage() {
+	suite.server.SetMessages(
+		`{"type":"error","message":"Failed to subscribe","reason":"BTC-XXX is not a valid product"}`,
+	)
+
+	_, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "BTC-XXX is not a valid product")
+}
+
+func (suite *CoinbaseSuite) TestReconnection() {
+	suite.server.SetMessages(
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.7","best_bid":"100.5","best_bid_size":"1",` +
+			`"best_ask":"101","best_ask_size":"2","side":"sell","last_size":"0.2","time":"2024-01-01T00:00:03Z"}`,
+	)
+	suite.server.SetDrops(1)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "coinbase listener stopped")
+	suite.Require().Len(suite.subscriptions(), 2)
+
+	// Ticks from both connections are separated by a gap tick
+	suite.Require().Len(ticks, 3)
+	for i, t := range ticks {
+		if i == 1 {
+			suite.Require().True(t.IsGap())
+			suite.Require().Equal(tick.KindGap, t.Kind)
+			suite.Require().Equal("BTC-USD", t.Pair)
+			suite.Require().False(t.Time.Before(t.GapStart))
+			continue
+		}
+		suite.Require().Equal(tick.KindTrade, t.Kind)
+	}
+}
+
+func (suite *CoinbaseSuite) TestParentNotRunning() {
+	suite.server.SetMessages(
+		`{"type":"ticker","product_id":"BTC-USD","price":"100.7","best_bid":"100.5","best_bid_size":"1",` +
+			`"best_ask":"101","best_ask_size":"2","side":"sell","last_size":"0.2","time":"2024-01-01T00:00:03Z"}`,
+	)
+	suite.server.SetDrops(1)
+
+	// The sentry is gone
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		Return(serviceerror.NewNotFound("workflow execution already completed"))
+	suite.temporal.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	acts, err := New(suite.temporal, suite.server.URL)
+	suite.Require().NoError(err)
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USD",
+		Kind:             tick.KindTrade,
+	})
+
+	// The listener stops without reconnecting
+	suite.Require().ErrorContains(err, signaling.ErrParentNotRunning.Error())
+	suite.Require().Len(suite.subscriptions(), 1)
+}
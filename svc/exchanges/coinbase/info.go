@@ -0,0 +1,6 @@
+package coinbase
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "coinbase"
+)
@@ -0,0 +1,98 @@
+package coinbase
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// tickerChannel is the name of the channel sending the best bid/ask and
+	// the last trade of a product.
+	tickerChannel = "ticker"
+
+	subscribeMessageType = "subscribe"
+	tickerMessageType    = "ticker"
+	errorMessageType     = "error"
+)
+
+// subscribeMessage is the message sent to subscribe to channels.
+type subscribeMessage struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+}
+
+// message is a message received from the websocket feed.
+// Only the fields used by this package are decoded.
+type message struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+
+	ProductID   string    `json:"product_id"`
+	Price       string    `json:"price"`
+	BestBid     string    `json:"best_bid"`
+	BestBidSize string    `json:"best_bid_size"`
+	BestAsk     string    `json:"best_ask"`
+	BestAskSize string    `json:"best_ask_size"`
+	Side        string    `json:"side"`
+	LastSize    string    `json:"last_size"`
+	TradeID     int64     `json:"trade_id"`
+	Time        time.Time `json:"time"`
+}
+
+func toBookTick(symbol string, msg message) (tick.Tick, error) {
+	bid, err := decimal.NewFromString(msg.BestBid)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	bidQty, err := decimal.NewFromString(msg.BestBidSize)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	ask, err := decimal.NewFromString(msg.BestAsk)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	askQty, err := decimal.NewFromString(msg.BestAskSize)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	return tick.FromExactBook(ExchangeName, symbol, msg.Time.UTC(), bid, bidQty, ask, askQty), nil
+}
+
+func toTradeTick(symbol string, msg message) (tick.Tick, error) {
+	price, err := decimal.NewFromString(msg.Price)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	qty, err := decimal.NewFromString(msg.LastSize)
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	// The side of the ticker is the side of the taker
+	side := tick.SideBuy
+	if msg.Side == "sell" {
+		side = tick.SideSell
+	}
+
+	t := tick.FromExactTrade(ExchangeName, symbol, msg.Time.UTC(), price, qty, side)
+	t.TradeID = strconv.FormatInt(msg.TradeID, 10)
+	return t, nil
+}
+
+func toCoinbaseProductID(symbol string) (string, error) {
+	base, quote, err := pair.ParsePair(symbol)
+	return fmt.Sprintf("%s-%s", base, quote), err
+}
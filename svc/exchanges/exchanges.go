@@ -8,6 +8,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/cryptellation/ticks/pkg/tick"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
@@ -27,6 +28,7 @@ type (
 		ParentWorkflowID string
 		Exchange         string
 		Symbol           string
+		Kind             tick.Kind
 	}
 
 	// ListenSymbolResults is the results for the ListenSymbolActivity.
@@ -38,6 +40,11 @@ type Exchanges interface {
 	Name() string
 	Register(w worker.Worker)
 
+	// LocalPairs returns the pairs of the exchange when they are defined
+	// locally instead of on the exchanges service (i.e. offline exchanges).
+	// It returns false if the exchange pairs should be checked on the exchanges service.
+	LocalPairs(exchange string) ([]string, bool)
+
 	ListenSymbolActivity(ctx context.Context, params ListenSymbolParams) (ListenSymbolResults, error)
 }
 
@@ -50,6 +50,21 @@ func (mr *MockExchangesMockRecorder) ListenSymbolActivity(ctx, params interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenSymbolActivity", reflect.TypeOf((*MockExchanges)(nil).ListenSymbolActivity), ctx, params)
 }
 
+// LocalPairs mocks base method.
+func (m *MockExchanges) LocalPairs(exchange string) ([]string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalPairs", exchange)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// LocalPairs indicates an expected call of LocalPairs.
+func (mr *MockExchangesMockRecorder) LocalPairs(exchange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalPairs", reflect.TypeOf((*MockExchanges)(nil).LocalPairs), exchange)
+}
+
 // Name mocks base method.
 func (m *MockExchanges) Name() string {
 	m.ctrl.T.Helper()
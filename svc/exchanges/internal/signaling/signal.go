@@ -0,0 +1,46 @@
+package signaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrParentNotRunning is returned when the workflow receiving the ticks is not
+// running anymore.
+var ErrParentNotRunning = errors.New("parent workflow is not running")
+
+// SignalNewTick sends the tick to the parent workflow.
+//
+// A failure only drops the tick, unless the context is done or the parent
+// workflow is not running anymore: the listener should then stop, so the error
+// is returned. The latter is permanent and wraps ErrParentNotRunning.
+func SignalNewTick(ctx context.Context, c temporalclient.Client, parentWorkflowID string, t tick.Tick) error {
+	err := c.SignalWorkflow(ctx, parentWorkflowID, "", signals.NewTickReceivedSignalName, t)
+	if err == nil {
+		return nil
+	} else if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	// Check if parent workflow is still running
+	desc, descErr := c.DescribeWorkflowExecution(ctx, parentWorkflowID, "")
+	var notFound *serviceerror.NotFound
+	switch {
+	case errors.As(descErr, &notFound):
+	case descErr != nil:
+		// The parent state is unknown, keep listening
+		return nil
+	case desc.GetWorkflowExecutionInfo().GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING:
+		return nil
+	}
+
+	return backoff.Permanent(fmt.Errorf("%w: %q: %w", ErrParentNotRunning, parentWorkflowID, err))
+}
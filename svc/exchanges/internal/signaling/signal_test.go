@@ -0,0 +1,102 @@
+//go:build unit
+// +build unit
+
+package signaling
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSignalSuite(t *testing.T) {
+	suite.Run(t, new(SignalSuite))
+}
+
+type SignalSuite struct {
+	suite.Suite
+
+	temporal *temporal.MockClient
+	tick     tick.Tick
+}
+
+func (suite *SignalSuite) SetupTest() {
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	suite.tick = tick.FromBook("exchange", "ETH-USDC", time.Now(), 1, 1, 2, 1)
+}
+
+func (suite *SignalSuite) expectSignal(err error) {
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, suite.tick).
+		Return(err)
+}
+
+func (suite *SignalSuite) expectStatus(status enums.WorkflowExecutionStatus) {
+	suite.temporal.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(&workflowservice.DescribeWorkflowExecutionResponse{
+			WorkflowExecutionInfo: &workflow.WorkflowExecutionInfo{Status: status},
+		}, nil)
+}
+
+func (suite *SignalSuite) TestSignal() {
+	suite.expectSignal(nil)
+	suite.Require().NoError(SignalNewTick(context.Background(), suite.temporal, "parent", suite.tick))
+}
+
+func (suite *SignalSuite) TestFailureWithRunningParent() {
+	// The tick is dropped but the listening goes on
+	suite.expectSignal(errors.New("unavailable"))
+	suite.expectStatus(enums.WORKFLOW_EXECUTION_STATUS_RUNNING)
+	suite.Require().NoError(SignalNewTick(context.Background(), suite.temporal, "parent", suite.tick))
+}
+
+func (suite *SignalSuite) TestFailureWithUnknownParentState() {
+	suite.expectSignal(errors.New("unavailable"))
+	suite.temporal.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, errors.New("unavailable"))
+	suite.Require().NoError(SignalNewTick(context.Background(), suite.temporal, "parent", suite.tick))
+}
+
+func (suite *SignalSuite) TestCompletedParent() {
+	suite.expectSignal(serviceerror.NewNotFound("workflow execution already completed"))
+	suite.expectStatus(enums.WORKFLOW_EXECUTION_STATUS_TERMINATED)
+
+	err := SignalNewTick(context.Background(), suite.temporal, "parent", suite.tick)
+	suite.Require().ErrorIs(err, ErrParentNotRunning)
+	var permanent *backoff.PermanentError
+	suite.Require().ErrorAs(err, &permanent)
+}
+
+func (suite *SignalSuite) TestUnknownParent() {
+	suite.expectSignal(serviceerror.NewNotFound("workflow not found"))
+	suite.temporal.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	err := SignalNewTick(context.Background(), suite.temporal, "parent", suite.tick)
+	suite.Require().ErrorIs(err, ErrParentNotRunning)
+}
+
+func (suite *SignalSuite) TestCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	suite.expectSignal(context.Canceled)
+	err := SignalNewTick(ctx, suite.temporal, "parent", suite.tick)
+	suite.Require().ErrorIs(err, context.Canceled)
+	suite.Require().NotErrorIs(err, ErrParentNotRunning)
+}
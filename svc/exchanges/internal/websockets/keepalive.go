@@ -0,0 +1,29 @@
+package websockets
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// KeepAlive will start a goroutine that will send the ping message on the
+// connection every interval until the context is done or the sending fails.
+// It should be the only writer on the connection while running.
+func KeepAlive(ctx context.Context, conn *websocket.Conn, interval time.Duration, ping []byte) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.WriteMessage(websocket.TextMessage, ping); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package websockets
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+// Session connects to a feed and streams it until the context is done or an
+// error occurs. It must call connected once the connection is established.
+// Errors that should not trigger a reconnection must be wrapped with backoff.Permanent.
+type Session func(ctx context.Context, connected func()) error
+
+// GapHandler is called when a connection is re-established after an
+// interruption, with the time of the disconnection and the time of the reconnection.
+type GapHandler func(start, end time.Time)
+
+// ListenWithReconnection runs the session and reconnects with the backoff when
+// it stops with an error, until the context is done or a permanent error occurs.
+// The backoff is reset each time a connection is established.
+func ListenWithReconnection(ctx context.Context, b backoff.BackOff, session Session, onGap GapHandler) error {
+	var wasConnected bool
+	var disconnectedAt time.Time
+	connected := func() {
+		b.Reset()
+		if !disconnectedAt.IsZero() {
+			onGap(disconnectedAt, time.Now().UTC())
+			disconnectedAt = time.Time{}
+		}
+		wasConnected = true
+	}
+
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		err := session(ctx, connected)
+		if err != nil && wasConnected && disconnectedAt.IsZero() {
+			disconnectedAt = time.Now().UTC()
+		}
+		return struct{}{}, err
+	}, backoff.WithBackOff(b), backoff.WithMaxElapsedTime(0))
+
+	// Context being done is the normal way to stop listening
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,91 @@
+//go:build unit
+// +build unit
+
+package websockets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReconnectSuite(t *testing.T) {
+	suite.Run(t, new(ReconnectSuite))
+}
+
+type ReconnectSuite struct {
+	suite.Suite
+}
+
+type gap struct {
+	start, end time.Time
+}
+
+func (suite *ReconnectSuite) TestReconnectUntilPermanentError() {
+	var calls int
+	var gaps []gap
+	errPermanent := errors.New("permanent")
+
+	err := ListenWithReconnection(context.Background(), backoff.NewConstantBackOff(time.Millisecond),
+		func(_ context.Context, connected func()) error {
+			calls++
+			switch calls {
+			case 1: // Connected, then disconnected
+				connected()
+				return errors.New("connection lost")
+			case 2: // Failed to connect
+				return errors.New("connection failed")
+			case 3: // Connected, then disconnected
+				connected()
+				return errors.New("connection lost")
+			case 4: // Connected, then subscription error
+				connected()
+				return backoff.Permanent(errPermanent)
+			default:
+				suite.FailNow("unexpected call")
+				return nil
+			}
+		}, func(start, end time.Time) {
+			gaps = append(gaps, gap{start: start, end: end})
+		})
+	suite.Require().ErrorIs(err, errPermanent)
+	suite.Require().Equal(4, calls)
+
+	// There is one gap for each reconnection
+	suite.Require().Len(gaps, 2)
+	for _, g := range gaps {
+		suite.Require().False(g.start.IsZero())
+		suite.Require().False(g.end.Before(g.start))
+	}
+}
+
+func (suite *ReconnectSuite) TestNoGapBeforeFirstConnection() {
+	var calls int
+	var gaps int
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := ListenWithReconnection(ctx, backoff.NewConstantBackOff(time.Millisecond),
+		func(ctx context.Context, connected func()) error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection failed")
+			}
+
+			// Connected, then stopped by context
+			connected()
+			cancel()
+			<-ctx.Done()
+			return nil
+		}, func(_, _ time.Time) {
+			gaps++
+		})
+	suite.Require().NoError(err)
+	suite.Require().Equal(3, calls)
+	suite.Require().Zero(gaps)
+}
@@ -0,0 +1,125 @@
+// Package websocketstest provides a local stand-in of an exchange websocket
+// to test the exchanges listeners.
+package websocketstest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Options are the options of the test server.
+type Options struct {
+	// ClosingMessage is sent to end the listener once the prepared messages
+	// have been sent on a connection that is not dropped.
+	ClosingMessage string
+	// Pong is the answer to the ping that the server waits for before sending
+	// the closing message. If empty, the server doesn't wait for a ping.
+	Pong string
+}
+
+// Server is a local websocket server: for each connection, it reads the
+// subscription, sends the prepared messages and either drops the connection
+// or ends the listener with the closing message.
+// It is safe to use from the test while the listener is connected.
+type Server struct {
+	// URL is the websocket URL of the server.
+	URL string
+
+	options Options
+	server  *httptest.Server
+
+	mu            sync.Mutex
+	messages      []string
+	drops         int
+	subscriptions []string
+	pings         []string
+}
+
+// NewServer starts a new test server that should be closed with Close.
+func NewServer(options Options) *Server {
+	s := &Server{options: options}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	return s
+}
+
+// SetMessages sets the messages sent on each new connection.
+func (s *Server) SetMessages(messages ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = messages
+}
+
+// SetDrops sets the number of connections closed by the server without ending
+// the listener, which forces it to reconnect.
+func (s *Server) SetDrops(drops int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drops = drops
+}
+
+// Subscriptions returns the first message received on each connection.
+func (s *Server) Subscriptions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subscriptions...)
+}
+
+// Pings returns the pings received before the closing message.
+func (s *Server) Pings() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.pings...)
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	_, sub, err := conn.ReadMessage()
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.subscriptions = append(s.subscriptions, string(sub))
+	drop := len(s.subscriptions) <= s.drops
+	messages := s.messages
+	s.mu.Unlock()
+
+	for _, msg := range messages {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			return
+		}
+	}
+	if drop {
+		return
+	}
+
+	if s.options.Pong != "" {
+		_, ping, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.pings = append(s.pings, string(ping))
+		s.mu.Unlock()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(s.options.Pong))
+	}
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(s.options.ClosingMessage))
+}
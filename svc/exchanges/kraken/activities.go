@@ -0,0 +1,201 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets"
+	"github.com/gorilla/websocket"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Activities is the struct that will handle the exchange.
+type Activities struct {
+	temporal     temporalclient.Client
+	websocketURL string
+}
+
+// New will create a new kraken exchange.
+func New(temporal temporalclient.Client, websocketURL string) (*Activities, error) {
+	if websocketURL == "" {
+		return nil, errors.New("websocket URL cannot be empty")
+	}
+
+	return &Activities{
+		temporal:     temporal,
+		websocketURL: websocketURL,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return false as kraken pairs are defined on the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return nil, false
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will listen to the symbol activity.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	krakenPair, err := toKrakenPair(params.Symbol)
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Start heartbeat on activity
+	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
+
+	// Listen to the feed, reconnecting when it is interrupted
+	err = websockets.ListenWithReconnection(ctx, backoff.NewExponentialBackOff(),
+		func(ctx context.Context, connected func()) error {
+			return a.listen(ctx, krakenPair, params, connected)
+		},
+		func(start, end time.Time) {
+			_ = a.signalNewTick(ctx, tick.FromGap(ExchangeName, params.Symbol, start, end), params)
+		})
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf("kraken listener stopped: %w", err)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+func (a *Activities) listen(
+	ctx context.Context,
+	krakenPair string,
+	params exchanges.ListenSymbolParams,
+	connected func(),
+) error {
+	// Connect to the websocket and subscribe to the channel corresponding to the kind
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, a.websocketURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	channel := tickerChannel
+	if params.Kind.OrDefault() == tick.KindTrade {
+		channel = tradeChannel
+	}
+	if err := conn.WriteJSON(subscribeMessage{
+		Event:        subscribeEvent,
+		Pair:         []string{krakenPair},
+		Subscription: subscription{Name: channel},
+	}); err != nil {
+		return err
+	}
+	connected()
+
+	// Close the connection when the session is done to stop reading
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	// Read messages until the connection is closed
+	return a.readMessages(ctx, conn, params)
+}
+
+func (a *Activities) signalNewTick(ctx context.Context, t tick.Tick, params exchanges.ListenSymbolParams) error {
+	return signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t)
+}
+
+func (a *Activities) readMessages(
+	ctx context.Context,
+	conn *websocket.Conn,
+	params exchanges.ListenSymbolParams,
+) error {
+	var last tick.Tick
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		// Check events (heartbeats, subscription status, etc)
+		if len(raw) > 0 && raw[0] == '{' {
+			var event eventMessage
+			if err := json.Unmarshal(raw, &event); err != nil {
+				continue
+			}
+			if event.Event == subscriptionStatusEvent && event.Status == errorStatus {
+				return backoff.Permanent(errors.New(event.ErrorMessage))
+			}
+			continue
+		}
+
+		// Convert channel data to ticks
+		ticks, err := toTicks(params.Symbol, raw)
+		if err != nil {
+			continue
+		}
+
+		// Send them to main workflow through Signal
+		for _, t := range ticks {
+			// Skip if same prices and quantities as last book tick
+			if t.Kind == tick.KindBook && t.Exact.Equal(last.Exact) {
+				continue
+			}
+			last = t
+
+			if err = a.signalNewTick(ctx, t, params); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toTicks(symbol string, raw []byte) ([]tick.Tick, error) {
+	msg, err := parseChannelMessage(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the message is for the right pair
+	msgPair, err := fromKrakenPair(msg.Pair)
+	if err != nil {
+		return nil, err
+	} else if msgPair != symbol {
+		return nil, fmt.Errorf("%w: unexpected pair %q", errInvalidMessage, msg.Pair)
+	}
+
+	switch msg.ChannelName {
+	case tickerChannel:
+		t, err := toBookTick(symbol, time.Now().UTC(), msg.Data[0])
+		if err != nil {
+			return nil, err
+		}
+		return []tick.Tick{t}, nil
+	case tradeChannel:
+		return toTradeTicks(symbol, msg.Data[0])
+	default:
+		return nil, fmt.Errorf("%w: unexpected channel %q", errInvalidMessage, msg.ChannelName)
+	}
+}
@@ -0,0 +1,163 @@
+//go:build unit
+// +build unit
+
+package kraken
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets/websocketstest"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+// closingMessage is sent by the test server to end the listener.
+const closingMessage = `{"event":"subscriptionStatus","status":"error","errorMessage":"Test server closed"}`
+
+func TestKrakenSuite(t *testing.T) {
+	suite.Run(t, new(KrakenSuite))
+}
+
+type KrakenSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	temporal *temporal.MockClient
+	server   *websocketstest.Server
+}
+
+func (suite *KrakenSuite) SetupTest() {
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	suite.server = websocketstest.NewServer(websocketstest.Options{
+		ClosingMessage: closingMessage,
+	})
+}
+
+func (suite *KrakenSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// subscriptions returns the subscriptions received by the test server.
+func (suite *KrakenSuite) subscriptions() []subscribeMessage {
+	subs := make([]subscribeMessage, 0)
+	for _, raw := range suite.server.Subscriptions() {
+		var sub subscribeMessage
+		suite.Require().NoError(json.Unmarshal([]byte(raw), &sub))
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (suite *KrakenSuite) listen(kind tick.Kind) ([]tick.Tick, error) {
+	acts, err := New(suite.temporal, suite.server.URL)
+	suite.Require().NoError(err)
+
+	ticks := make([]tick.Tick, 0)
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, arg any) error {
+			ticks = append(ticks, arg.(tick.Tick))
+			return nil
+		}).AnyTimes()
+
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USD",
+		Kind:             kind,
+	})
+	return ticks, err
+}
+
+func (suite *KrakenSuite) TestBookTicks() {
+	suite.server.SetMessages(
+		`{"event":"systemStatus","status":"online"}`,
+		`{"event":"subscriptionStatus","status":"subscribed","pair":"XBT/USD"}`,
+		`[340,{"a":["101.0",1,"2.0"],"b":["100.0",3,"1.5"],"c":["100.5","0.1"]},"ticker","XBT/USD"]`,
+		`{"event":"heartbeat"}`,
+		`[340,{"a":["101.0",1,"2.0"],"b":["100.0",3,"1.5"],"c":["100.6","0.1"]},"ticker","XBT/USD"]`,
+		`[340,{"a":["101.0",1,"2.0"],"b":["100.0",3,"1.4"],"c":["100.7","0.1"]},"ticker","XBT/USD"]`,
+		`[340,{"a":["101.0",1,"2.0"],"b":["100.5",1,"1.0"],"c":["100.8","0.1"]},"ticker","XBT/USD"]`,
+	)
+
+	ticks, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "kraken listener stopped")
+
+	suite.Require().Len(suite.subscriptions(), 1)
+	suite.Require().Equal([]string{"XBT/USD"}, suite.subscriptions()[0].Pair)
+	suite.Require().Equal(tickerChannel, suite.subscriptions()[0].Subscription.Name)
+
+	// Second ticker is skipped as it has the same bid/ask and quantities
+	suite.Require().Len(ticks, 3)
+	suite.Require().Equal(ExchangeName, ticks[0].Exchange)
+	suite.Require().Equal("BTC-USD", ticks[0].Pair)
+	suite.Require().Equal(100.0, ticks[0].Bid)
+	suite.Require().Equal(1.5, ticks[0].BidQty)
+	suite.Require().Equal(101.0, ticks[0].Ask)
+	suite.Require().Equal(100.0, ticks[1].Bid)
+	suite.Require().Equal(1.4, ticks[1].BidQty)
+	suite.Require().Equal(100.5, ticks[2].Bid)
+}
+
+func (suite *KrakenSuite) TestTradeTicks() {
+	suite.server.SetMessages(
+		`[337,[["100.7","0.2","1704067203.500000","s","m",""],` +
+			`["100.8","0.1","1704067203.600000","b","l",""]],"trade","XBT/USD"]`,
+	)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "kraken listener stopped")
+
+	suite.Require().Equal(tradeChannel, suite.subscriptions()[0].Subscription.Name)
+	suite.Require().Len(ticks, 2)
+	suite.Require().Equal(tick.KindTrade, ticks[0].Kind)
+	suite.Require().Equal(100.7, ticks[0].Price)
+	suite.Require().Equal(0.2, ticks[0].Qty)
+	suite.Require().Equal(tick.SideSell, ticks[0].Side)
+	suite.Require().Equal(int64(1704067203500), ticks[0].Time.UnixMilli())
+	suite.Require().Equal(tick.SideBuy, ticks[1].Side)
+	suite.Require().Equal("1704067203.500000-0", ticks[0].TradeID)
+	suite.Require().Equal("1704067203.600000-1", ticks[1].TradeID)
+}
+
+func (suite *KrakenSuite) TestSubscriptionError() {
+	suite.server.SetMessages(
+		`{"event":"subscriptionStatus","status":"error","errorMessage":"Currency pair not supported XBT/XXX"}`,
+	)
+
+	_, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "Currency pair not supported")
+}
+
+func (suite *KrakenSuite) TestReconnection() {
+	suite.server.SetMessages(
+		`[337,[["100.7","0.2","1704067203.500000","s","m",""],` +
+			`["100.8","0.1","1704067203.600000","b","l",""]],"trade","XBT/USD"]`,
+	)
+	suite.server.SetDrops(1)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "kraken listener stopped")
+	suite.Require().Len(suite.subscriptions(), 2)
+
+	// Ticks from both connections are separated by a gap tick
+	suite.Require().Len(ticks, 5)
+	for i, t := range ticks {
+		if i == 2 {
+			suite.Require().True(t.IsGap())
+			suite.Require().Equal(tick.KindGap, t.Kind)
+			suite.Require().Equal("BTC-USD", t.Pair)
+			suite.Require().False(t.Time.Before(t.GapStart))
+			continue
+		}
+		suite.Require().Equal(tick.KindTrade, t.Kind)
+	}
+}
@@ -0,0 +1,6 @@
+package kraken
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "kraken"
+)
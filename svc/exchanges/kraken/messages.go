@@ -0,0 +1,158 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	tickerChannel = "ticker"
+	tradeChannel  = "trade"
+
+	subscribeEvent          = "subscribe"
+	subscriptionStatusEvent = "subscriptionStatus"
+	errorStatus             = "error"
+)
+
+var (
+	// errInvalidMessage is the error when a channel message has not the expected format.
+	errInvalidMessage = errors.New("invalid kraken message")
+)
+
+// subscribeMessage is the message sent to subscribe to a channel.
+type subscribeMessage struct {
+	Event        string       `json:"event"`
+	Pair         []string     `json:"pair"`
+	Subscription subscription `json:"subscription"`
+}
+
+type subscription struct {
+	Name string `json:"name"`
+}
+
+// eventMessage is a message received from the websocket that is not
+// related to a channel data (i.e. heartbeat or subscription status).
+type eventMessage struct {
+	Event        string `json:"event"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// channelMessage is a data message received from a channel. It is sent as
+// an array: [channelID, data..., channelName, pair].
+type channelMessage struct {
+	Data        []json.RawMessage
+	ChannelName string
+	Pair        string
+}
+
+func parseChannelMessage(raw []byte) (channelMessage, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return channelMessage{}, err
+	}
+	if len(elements) < 4 {
+		return channelMessage{}, fmt.Errorf("%w: %d elements", errInvalidMessage, len(elements))
+	}
+
+	var msg channelMessage
+	if err := json.Unmarshal(elements[len(elements)-2], &msg.ChannelName); err != nil {
+		return channelMessage{}, err
+	}
+	if err := json.Unmarshal(elements[len(elements)-1], &msg.Pair); err != nil {
+		return channelMessage{}, err
+	}
+	msg.Data = elements[1 : len(elements)-2]
+
+	return msg, nil
+}
+
+// tickerData is the data of a ticker channel message. Best ask and bid are
+// sent as [price, wholeLotVolume, lotVolume], mixing strings and numbers.
+type tickerData struct {
+	Ask []json.Number `json:"a"`
+	Bid []json.Number `json:"b"`
+}
+
+func toBookTick(symbol string, t time.Time, data json.RawMessage) (tick.Tick, error) {
+	var ticker tickerData
+	if err := json.Unmarshal(data, &ticker); err != nil {
+		return tick.Tick{}, err
+	}
+	if len(ticker.Ask) < 3 || len(ticker.Bid) < 3 {
+		return tick.Tick{}, fmt.Errorf("%w: incomplete ticker", errInvalidMessage)
+	}
+
+	bid, err := decimal.NewFromString(ticker.Bid[0].String())
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	bidQty, err := decimal.NewFromString(ticker.Bid[2].String())
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	ask, err := decimal.NewFromString(ticker.Ask[0].String())
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	askQty, err := decimal.NewFromString(ticker.Ask[2].String())
+	if err != nil {
+		return tick.Tick{}, err
+	}
+
+	return tick.FromExactBook(ExchangeName, symbol, t, bid, bidQty, ask, askQty), nil
+}
+
+// toTradeTicks converts the data of a trade channel message. Trades are sent
+// as [[price, volume, time, side, orderType, misc], ...]. As they have no ID,
+// the time and position of the trade in the message are used instead.
+func toTradeTicks(symbol string, data json.RawMessage) ([]tick.Tick, error) {
+	var trades [][]string
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return nil, err
+	}
+
+	ticks := make([]tick.Tick, 0, len(trades))
+	for i, trade := range trades {
+		if len(trade) < 4 {
+			return nil, fmt.Errorf("%w: incomplete trade", errInvalidMessage)
+		}
+
+		price, err := decimal.NewFromString(trade[0])
+		if err != nil {
+			return nil, err
+		}
+
+		qty, err := decimal.NewFromString(trade[1])
+		if err != nil {
+			return nil, err
+		}
+
+		secs, err := strconv.ParseFloat(trade[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		t := time.UnixMicro(int64(secs * 1e6)).UTC()
+
+		// The side of the trade is the side of the taker
+		side := tick.SideBuy
+		if trade[3] == "s" {
+			side = tick.SideSell
+		}
+
+		tradeTick := tick.FromExactTrade(ExchangeName, symbol, t, price, qty, side)
+		tradeTick.TradeID = fmt.Sprintf("%s-%d", trade[2], i)
+		ticks = append(ticks, tradeTick)
+	}
+
+	return ticks, nil
+}
@@ -0,0 +1,75 @@
+package kraken
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+)
+
+var (
+	// krakenAssetAliases are the Kraken asset names that differ from the
+	// usual asset names, in both directions.
+	krakenAssetAliases = map[string]string{
+		"XBT": "BTC",
+		"XDG": "DOGE",
+	}
+
+	// legacyAssets are the assets that Kraken prefixes with X (crypto) or Z
+	// (fiat) in its legacy names (i.e. "XXBT" or "ZUSD").
+	legacyAssets = map[string]bool{
+		"XXBT": true, "XXDG": true, "XETH": true, "XETC": true, "XLTC": true,
+		"XMLN": true, "XREP": true, "XXLM": true, "XXMR": true, "XXRP": true,
+		"XZEC": true, "ZUSD": true, "ZEUR": true, "ZGBP": true, "ZJPY": true,
+		"ZCAD": true, "ZAUD": true, "ZCHF": true,
+	}
+)
+
+// toKrakenAsset converts an asset to its Kraken websocket name (i.e. "BTC" to "XBT").
+func toKrakenAsset(asset string) string {
+	for kraken, usual := range krakenAssetAliases {
+		if usual == asset {
+			return kraken
+		}
+	}
+	return asset
+}
+
+// fromKrakenAsset converts a Kraken asset name, legacy or not, to its
+// usual name (i.e. "XXBT" or "XBT" to "BTC", "ZUSD" to "USD").
+func fromKrakenAsset(asset string) string {
+	if legacyAssets[asset] {
+		asset = asset[1:]
+	}
+
+	if usual, ok := krakenAssetAliases[asset]; ok {
+		return usual
+	}
+	return asset
+}
+
+// toKrakenPair converts a cryptellation pair (i.e. "BTC-USD") to a Kraken
+// websocket pair (i.e. "XBT/USD").
+func toKrakenPair(symbol string) (string, error) {
+	base, quote, err := pair.ParsePair(symbol)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", toKrakenAsset(base), toKrakenAsset(quote)), nil
+}
+
+// fromKrakenPair converts a Kraken pair, either from websocket (i.e. "XBT/USD")
+// or legacy (i.e. "XXBTZUSD"), to a cryptellation pair (i.e. "BTC-USD").
+func fromKrakenPair(krakenPair string) (string, error) {
+	var base, quote string
+	if parts := strings.Split(krakenPair, "/"); len(parts) == 2 {
+		base, quote = parts[0], parts[1]
+	} else if len(krakenPair) == 8 && legacyAssets[krakenPair[:4]] && legacyAssets[krakenPair[4:]] {
+		base, quote = krakenPair[:4], krakenPair[4:]
+	} else {
+		return "", fmt.Errorf("invalid kraken pair %q", krakenPair)
+	}
+
+	return pair.FormatPair(fromKrakenAsset(base), fromKrakenAsset(quote)), nil
+}
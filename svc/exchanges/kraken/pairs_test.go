@@ -0,0 +1,56 @@
+//go:build unit
+// +build unit
+
+package kraken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestPairsSuite(t *testing.T) {
+	suite.Run(t, new(PairsSuite))
+}
+
+type PairsSuite struct {
+	suite.Suite
+}
+
+func (suite *PairsSuite) TestToKrakenPair() {
+	cases := map[string]string{
+		"BTC-USD":  "XBT/USD",
+		"ETH-BTC":  "ETH/XBT",
+		"DOGE-EUR": "XDG/EUR",
+		"SOL-USDT": "SOL/USDT",
+	}
+
+	for symbol, expected := range cases {
+		krakenPair, err := toKrakenPair(symbol)
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, krakenPair, symbol)
+	}
+
+	_, err := toKrakenPair("BTCUSD")
+	suite.Require().Error(err)
+}
+
+func (suite *PairsSuite) TestFromKrakenPair() {
+	cases := map[string]string{
+		"XBT/USD":  "BTC-USD",
+		"XDG/EUR":  "DOGE-EUR",
+		"SOL/USDT": "SOL-USDT",
+		"XXBTZUSD": "BTC-USD",
+		"XETHXXBT": "ETH-BTC",
+		"XXDGZEUR": "DOGE-EUR",
+	}
+
+	for krakenPair, expected := range cases {
+		symbol, err := fromKrakenPair(krakenPair)
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, symbol, krakenPair)
+	}
+
+	_, err := fromKrakenPair("UNKNOWN")
+	suite.Require().Error(err)
+}
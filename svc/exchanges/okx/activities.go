@@ -0,0 +1,194 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets"
+	"github.com/gorilla/websocket"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// pingInterval is the interval between two pings, as OKX closes
+// connections without message after 30 seconds.
+const pingInterval = 20 * time.Second
+
+// Activities is the struct that will handle the exchange.
+type Activities struct {
+	temporal     temporalclient.Client
+	websocketURL string
+	pingInterval time.Duration
+}
+
+// New will create a new okx exchange.
+func New(temporal temporalclient.Client, websocketURL string) (*Activities, error) {
+	if websocketURL == "" {
+		return nil, errors.New("websocket URL cannot be empty")
+	}
+
+	return &Activities{
+		temporal:     temporal,
+		websocketURL: websocketURL,
+		pingInterval: pingInterval,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return false as okx pairs are defined on the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return nil, false
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will listen to the symbol activity.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	instrumentID, err := toOKXInstrumentID(params.Symbol)
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Start heartbeat on activity
+	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
+
+	// Listen to the feed, reconnecting when it is interrupted
+	err = websockets.ListenWithReconnection(ctx, backoff.NewExponentialBackOff(),
+		func(ctx context.Context, connected func()) error {
+			return a.listen(ctx, instrumentID, params, connected)
+		},
+		func(start, end time.Time) {
+			_ = a.signalNewTick(ctx, tick.FromGap(ExchangeName, params.Symbol, start, end), params)
+		})
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf("okx listener stopped: %w", err)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+func (a *Activities) listen(
+	ctx context.Context,
+	instrumentID string,
+	params exchanges.ListenSymbolParams,
+	connected func(),
+) error {
+	// Connect to the websocket and subscribe to the channel corresponding to the kind
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, a.websocketURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	channel := tickersChannel
+	if params.Kind.OrDefault() == tick.KindTrade {
+		channel = tradesChannel
+	}
+	if err := conn.WriteJSON(subscribeMessage{
+		Operation: subscribeOperation,
+		Args:      []argument{{Channel: channel, InstrumentID: instrumentID}},
+	}); err != nil {
+		return err
+	}
+	connected()
+
+	// Close the connection when the session is done to stop reading
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	// Keep the connection alive with pings
+	websockets.KeepAlive(ctx, conn, a.pingInterval, []byte(pingMessage))
+
+	// Read messages until the connection is closed
+	return a.readMessages(ctx, conn, params)
+}
+
+func (a *Activities) signalNewTick(ctx context.Context, t tick.Tick, params exchanges.ListenSymbolParams) error {
+	return signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t)
+}
+
+func (a *Activities) readMessages(
+	ctx context.Context,
+	conn *websocket.Conn,
+	params exchanges.ListenSymbolParams,
+) error {
+	var last tick.Tick
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		// Skip pong responses as they are not JSON
+		if string(raw) == pongMessage {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+
+		// Check events (subscription, error, etc)
+		if msg.Event == errorEvent {
+			return backoff.Permanent(fmt.Errorf("%s: %s", msg.Code, msg.Message))
+		} else if msg.Event != "" {
+			continue
+		}
+
+		// Convert channel data to ticks
+		var ticks []tick.Tick
+		switch msg.Arg.Channel {
+		case tickersChannel:
+			ticks, err = toBookTicks(params.Symbol, msg)
+		case tradesChannel:
+			ticks, err = toTradeTicks(params.Symbol, msg)
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		// Send them to main workflow through Signal
+		for _, t := range ticks {
+			// Skip if same prices and quantities as last book tick
+			if t.Kind == tick.KindBook && t.Exact.Equal(last.Exact) {
+				continue
+			}
+			last = t
+
+			if err = a.signalNewTick(ctx, t, params); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+//go:build unit
+// +build unit
+
+package okx
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/websockets/websocketstest"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+// closingMessage is sent by the test server to end the listener.
+const closingMessage = `{"event":"error","code":"0","msg":"Test server closed"}`
+
+func TestOKXSuite(t *testing.T) {
+	suite.Run(t, new(OKXSuite))
+}
+
+type OKXSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	temporal *temporal.MockClient
+	server   *websocketstest.Server
+}
+
+func (suite *OKXSuite) SetupTest() {
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	suite.server = websocketstest.NewServer(websocketstest.Options{
+		ClosingMessage: closingMessage,
+		Pong:           pongMessage,
+	})
+}
+
+func (suite *OKXSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// subscriptions returns the subscriptions received by the test server.
+func (suite *OKXSuite) subscriptions() []subscribeMessage {
+	subs := make([]subscribeMessage, 0)
+	for _, raw := range suite.server.Subscriptions() {
+		var sub subscribeMessage
+		suite.Require().NoError(json.Unmarshal([]byte(raw), &sub))
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+func (suite *OKXSuite) listen(kind tick.Kind) ([]tick.Tick, error) {
+	acts, err := New(suite.temporal, suite.server.URL)
+	suite.Require().NoError(err)
+	acts.pingInterval = 10 * time.Millisecond
+
+	ticks := make([]tick.Tick, 0)
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, arg any) error {
+			ticks = append(ticks, arg.(tick.Tick))
+			return nil
+		}).AnyTimes()
+
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+		Kind:             kind,
+	})
+	return ticks, err
+}
+
+func (suite *OKXSuite) TestBookTicks() {
+	suite.server.SetMessages(
+		`{"event":"subscribe","arg":{"channel":"tickers","instId":"BTC-USDT"},"connId":"id"}`,
+		`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"100.5",`+
+			`"bidPx":"100","bidSz":"1.5","askPx":"101","askSz":"2","ts":"1704067201000"}]}`,
+		`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"100.6",`+
+			`"bidPx":"100","bidSz":"1.5","askPx":"101","askSz":"2","ts":"1704067201500"}]}`,
+		`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"100.6",`+
+			`"bidPx":"100","bidSz":"1.4","askPx":"101","askSz":"2","ts":"1704067202000"}]}`,
+		`{"arg":{"channel":"tickers","instId":"BTC-USDT"},"data":[{"instId":"BTC-USDT","last":"100.7",`+
+			`"bidPx":"100.5","bidSz":"1","askPx":"101","askSz":"2","ts":"1704067203000"}]}`,
+	)
+
+	ticks, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "okx listener stopped")
+
+	subs := suite.subscriptions()
+	suite.Require().Len(subs, 1)
+	suite.Require().Equal([]argument{{Channel: tickersChannel, InstrumentID: "BTC-USDT"}}, subs[0].Args)
+	suite.Require().Equal([]string{pingMessage}, suite.server.Pings())
+
+	// Second ticker is skipped as it has the same bid/ask and quantities
+	suite.Require().Len(ticks, 3)
+	suite.Require().Equal(ExchangeName, ticks[0].Exchange)
+	suite.Require().Equal("BTC-USDT", ticks[0].Pair)
+	suite.Require().Equal(100.0, ticks[0].Bid)
+	suite.Require().Equal(1.5, ticks[0].BidQty)
+	suite.Require().Equal(101.0, ticks[0].Ask)
+	suite.Require().Equal(int64(1704067201000), ticks[0].Time.UnixMilli())
+	suite.Require().Equal(100.0, ticks[1].Bid)
+	suite.Require().Equal(1.4, ticks[1].BidQty)
+	suite.Require().Equal(100.5, ticks[2].Bid)
+}
+
+func (suite *OKXSuite) TestTradeTicks() {
+	suite.server.SetMessages(
+		`{"arg":{"channel":"trades","instId":"BTC-USDT"},"data":[` +
+			`{"instId":"BTC-USDT","tradeId":"1","px":"100.7","sz":"0.2","side":"sell","ts":"1704067203500"},` +
+			`{"instId":"BTC-USDT","tradeId":"2","px":"100.8","sz":"0.1","side":"buy","ts":"1704067203600"}]}`,
+	)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "okx listener stopped")
+
+	suite.Require().Equal(tradesChannel, suite.subscriptions()[0].Args[0].Channel)
+	suite.Require().Len(ticks, 2)
+	suite.Require().Equal(tick.KindTrade, ticks[0].Kind)
+	suite.Require().Equal(100.7, ticks[0].Price)
+	suite.Require().Equal(0.2, ticks[0].Qty)
+	suite.Require().Equal(tick.SideSell, ticks[0].Side)
+	suite.Require().Equal(tick.SideBuy, ticks[1].Side)
+	suite.Require().Equal("1", ticks[0].TradeID)
+	suite.Require().Equal("2", ticks[1].TradeID)
+}
+
+func (suite *OKXSuite) TestErrorEvent() {
+	suite.server.SetMessages(
+		`{"event":"error","code":"60018","msg":"Wrong URL or channel:tickers,instId:BTC-XXX doesn't exist."}`,
+	)
+
+	_, err := suite.listen(tick.KindBook)
+	suite.Require().ErrorContains(err, "60018")
+}
+
+func (suite *OKXSuite) TestReconnection() {
+	suite.server.SetMessages(
+		`{"arg":{"channel":"trades","instId":"BTC-USDT"},"data":[` +
+			`{"instId":"BTC-USDT","tradeId":"1","px":"100.7","sz":"0.2","side":"sell","ts":"1704067203500"},` +
+			`{"instId":"BTC-USDT","tradeId":"2","px":"100.8","sz":"0.1","side":"buy","ts":"1704067203600"}]}`,
+	)
+	suite.server.SetDrops(1)
+
+	ticks, err := suite.listen(tick.KindTrade)
+	suite.Require().ErrorContains(err, "okx listener stopped")
+	suite.Require().Len(suite.subscriptions(), 2)
+
+	// Ticks from both connections are separated by a gap tick
+	suite.Require().Len(ticks, 5)
+	for i, t := range ticks {
+		if i == 2 {
+			suite.Require().True(t.IsGap())
+			suite.Require().Equal(tick.KindGap, t.Kind)
+			suite.Require().Equal("BTC-USDT", t.Pair)
+			suite.Require().False(t.Time.Before(t.GapStart))
+			continue
+		}
+		suite.Require().Equal(tick.KindTrade, t.Kind)
+	}
+}
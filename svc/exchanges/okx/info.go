@@ -0,0 +1,6 @@
+package okx
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "okx"
+)
@@ -0,0 +1,163 @@
+package okx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// tickersChannel is the channel of the last price and best bid/ask of an instrument.
+	tickersChannel = "tickers"
+	// tradesChannel is the channel of the trades of an instrument.
+	tradesChannel = "trades"
+
+	subscribeOperation = "subscribe"
+	errorEvent         = "error"
+
+	// pingMessage is the raw message sent to keep the connection alive.
+	pingMessage = "ping"
+	// pongMessage is the raw message received as a response to a ping.
+	pongMessage = "pong"
+)
+
+var (
+	// errInvalidMessage is the error when a channel message has not the expected format.
+	errInvalidMessage = errors.New("invalid okx message")
+)
+
+// subscribeMessage is the message sent to subscribe to channels.
+type subscribeMessage struct {
+	Operation string     `json:"op"`
+	Args      []argument `json:"args"`
+}
+
+type argument struct {
+	Channel      string `json:"channel"`
+	InstrumentID string `json:"instId"`
+}
+
+// message is a message received from the websocket, either an event
+// (i.e. subscription or error) or a channel data message.
+type message struct {
+	Event   string `json:"event"`
+	Code    string `json:"code"`
+	Message string `json:"msg"`
+
+	Arg  argument        `json:"arg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// tickerData is the data of the tickers channel.
+type tickerData struct {
+	BidPrice string `json:"bidPx"`
+	BidSize  string `json:"bidSz"`
+	AskPrice string `json:"askPx"`
+	AskSize  string `json:"askSz"`
+	TS       string `json:"ts"`
+}
+
+// tradeData is the data of the trades channel.
+type tradeData struct {
+	ID    string `json:"tradeId"`
+	Price string `json:"px"`
+	Size  string `json:"sz"`
+	Side  string `json:"side"`
+	TS    string `json:"ts"`
+}
+
+func toBookTicks(symbol string, msg message) ([]tick.Tick, error) {
+	var tickers []tickerData
+	if err := json.Unmarshal(msg.Data, &tickers); err != nil {
+		return nil, err
+	}
+
+	ticks := make([]tick.Tick, 0, len(tickers))
+	for _, ticker := range tickers {
+		bid, err := decimal.NewFromString(ticker.BidPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		bidQty, err := decimal.NewFromString(ticker.BidSize)
+		if err != nil {
+			return nil, err
+		}
+
+		ask, err := decimal.NewFromString(ticker.AskPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		askQty, err := decimal.NewFromString(ticker.AskSize)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := parseTimestamp(ticker.TS)
+		if err != nil {
+			return nil, err
+		}
+
+		ticks = append(ticks, tick.FromExactBook(ExchangeName, symbol, t, bid, bidQty, ask, askQty))
+	}
+
+	return ticks, nil
+}
+
+func toTradeTicks(symbol string, msg message) ([]tick.Tick, error) {
+	var trades []tradeData
+	if err := json.Unmarshal(msg.Data, &trades); err != nil {
+		return nil, err
+	}
+
+	ticks := make([]tick.Tick, 0, len(trades))
+	for _, trade := range trades {
+		price, err := decimal.NewFromString(trade.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		qty, err := decimal.NewFromString(trade.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := parseTimestamp(trade.TS)
+		if err != nil {
+			return nil, err
+		}
+
+		// The side of the trade is the side of the taker
+		side := tick.SideBuy
+		if trade.Side == "sell" {
+			side = tick.SideSell
+		}
+
+		tradeTick := tick.FromExactTrade(ExchangeName, symbol, t, price, qty, side)
+		tradeTick.TradeID = trade.ID
+		ticks = append(ticks, tradeTick)
+	}
+
+	return ticks, nil
+}
+
+// parseTimestamp parses a timestamp in milliseconds sent as a string.
+func parseTimestamp(ts string) (time.Time, error) {
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid timestamp %q", errInvalidMessage, ts)
+	}
+	return time.UnixMilli(ms).UTC(), nil
+}
+
+func toOKXInstrumentID(symbol string) (string, error) {
+	base, quote, err := pair.ParsePair(symbol)
+	return fmt.Sprintf("%s-%s", base, quote), err
+}
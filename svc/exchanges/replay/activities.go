@@ -0,0 +1,154 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// heartbeatInterval is the interval between two heartbeats while waiting for
+// the next tick to replay.
+const heartbeatInterval = 300 * time.Millisecond
+
+// Config is the configuration of the replay exchange.
+type Config struct {
+	// Directory is the directory containing the recorded ticks, with one
+	// file per pair named after it (i.e. "BTC-USDT.jsonl" or "BTC-USDT.csv").
+	Directory string
+	// Speed is the replay speed compared to the original pace: 1 replays at
+	// the original pace, 10 replays 10 times faster and 0 replays as fast as possible.
+	Speed float64
+}
+
+// Validate checks that the configuration is valid.
+func (c Config) Validate() error {
+	if c.Directory == "" {
+		return errors.New("directory must be provided")
+	}
+	if c.Speed < 0 {
+		return errors.New("speed cannot be negative")
+	}
+	return nil
+}
+
+// Activities is the struct that will handle the replay exchange.
+type Activities struct {
+	temporal temporalclient.Client
+	config   Config
+}
+
+// New will create a new replay exchange.
+func New(temporal temporalclient.Client, config Config) (*Activities, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Activities{
+		temporal: temporal,
+		config:   config,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return the pairs that have recorded ticks as the replay
+// exchange is unknown to the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	pairs, err := listRecordedPairs(a.config.Directory)
+	if err != nil {
+		return []string{}, true
+	}
+	return pairs, true
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will replay the recorded ticks of the symbol until
+// there is no more ticks or the context is done.
+// Replayed ticks keep their recorded time but are sent with the replay exchange name.
+// The offset of the next tick is recorded in the heartbeat details, so that a
+// retried activity resumes the replay where the previous attempt stopped.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	ticks, err := readRecordedTicks(a.config.Directory, params.Symbol, params.Kind)
+	if err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Resume from the offset of the previous attempt, if any
+	var offset int
+	if activity.HasHeartbeatDetails(ctx) {
+		if err := activity.GetHeartbeatDetails(ctx, &offset); err != nil {
+			return exchanges.ListenSymbolResults{}, fmt.Errorf("getting replay offset: %w", err)
+		}
+	}
+
+	// Replay ticks
+	for i := offset; i < len(ticks); i++ {
+		t := ticks[i]
+
+		// Wait for the delay between previous and current tick
+		if i > offset && !wait(ctx, a.delay(ticks[i-1], t), i) {
+			return exchanges.ListenSymbolResults{}, nil
+		}
+
+		// Send it to main workflow through Signal
+		t.Exchange = ExchangeName
+		t.Pair = params.Symbol
+		if err := signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t); err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return exchanges.ListenSymbolResults{}, nil
+			}
+			return exchanges.ListenSymbolResults{}, err
+		}
+		activity.RecordHeartbeat(ctx, i+1)
+	}
+
+	return exchanges.ListenSymbolResults{}, nil
+}
+
+// wait waits for the delay while heartbeating the offset of the next tick. It
+// returns false if the context is done in the meantime.
+func wait(ctx context.Context, delay time.Duration, offset int) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-heartbeat.C:
+			activity.RecordHeartbeat(ctx, offset)
+		}
+	}
+}
+
+// delay returns the time to wait between two ticks based on the replay speed.
+func (a *Activities) delay(previous, current tick.Tick) time.Duration {
+	if a.config.Speed == 0 {
+		return 0
+	}
+	return time.Duration(float64(current.Time.Sub(previous.Time)) / a.config.Speed)
+}
@@ -0,0 +1,108 @@
+//go:build unit
+// +build unit
+
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReplaySuite(t *testing.T) {
+	suite.Run(t, new(ReplaySuite))
+}
+
+type ReplaySuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	temporal *temporal.MockClient
+	acts     *Activities
+}
+
+func (suite *ReplaySuite) SetupTest() {
+	directory := suite.T().TempDir()
+	jsonl := `{"time":"2024-01-01T00:00:01Z","kind":"book","exact":{"bid":"98","ask":"100"}}
+{"time":"2024-01-01T00:00:02Z","kind":"book","exact":{"bid":"99","ask":"101"}}
+{"time":"2024-01-01T00:00:03Z","kind":"book","exact":{"bid":"100","ask":"102"}}
+`
+	suite.Require().NoError(os.WriteFile(filepath.Join(directory, "BTC-USDT.jsonl"), []byte(jsonl), 0o600))
+
+	suite.temporal = temporal.NewMockClient(gomock.NewController(suite.T()))
+	acts, err := New(suite.temporal, Config{Directory: directory})
+	suite.Require().NoError(err)
+	suite.acts = acts
+}
+
+func (suite *ReplaySuite) replay(env *testsuite.TestActivityEnvironment) ([]tick.Tick, error) {
+	ticks := make([]tick.Tick, 0)
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, arg any) error {
+			ticks = append(ticks, arg.(tick.Tick))
+			return nil
+		}).AnyTimes()
+
+	env.RegisterActivity(suite.acts.ListenSymbolActivity)
+	_, err := env.ExecuteActivity(suite.acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+		Kind:             tick.KindBook,
+	})
+	return ticks, err
+}
+
+func (suite *ReplaySuite) TestReplay() {
+	ticks, err := suite.replay(suite.NewTestActivityEnvironment())
+	suite.Require().NoError(err)
+
+	suite.Require().Len(ticks, 3)
+	for i, t := range ticks {
+		suite.Require().Equal(ExchangeName, t.Exchange)
+		suite.Require().Equal("BTC-USDT", t.Pair)
+		suite.Require().Equal(time.Date(2024, 1, 1, 0, 0, i+1, 0, time.UTC), t.Time)
+	}
+}
+
+func (suite *ReplaySuite) TestResumeFromHeartbeat() {
+	// A previous attempt has already sent the first two ticks
+	env := suite.NewTestActivityEnvironment()
+	env.SetHeartbeatDetails(2)
+
+	ticks, err := suite.replay(env)
+	suite.Require().NoError(err)
+	suite.Require().Len(ticks, 1)
+	suite.Require().Equal(time.Date(2024, 1, 1, 0, 0, 3, 0, time.UTC), ticks[0].Time)
+}
+
+func (suite *ReplaySuite) TestParentNotRunning() {
+	suite.temporal.EXPECT().
+		SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+		Return(serviceerror.NewNotFound("workflow execution already completed"))
+	suite.temporal.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(suite.acts.ListenSymbolActivity)
+	_, err := env.ExecuteActivity(suite.acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+		Kind:             tick.KindBook,
+	})
+	suite.Require().ErrorContains(err, signaling.ErrParentNotRunning.Error())
+}
@@ -0,0 +1,6 @@
+package replay
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "replay"
+)
@@ -0,0 +1,219 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// jsonLinesExtension is the extension of the JSON lines recorded files.
+	jsonLinesExtension = ".jsonl"
+	// csvExtension is the extension of the CSV recorded files.
+	csvExtension = ".csv"
+)
+
+var (
+	// ErrNoRecord is the error when there is no recorded file for a pair.
+	ErrNoRecord = errors.New("no recorded ticks")
+)
+
+// listRecordedPairs returns the pairs that have a recorded file in the directory.
+// Files are expected to be named after the pair (i.e. "BTC-USDT.jsonl" or "BTC-USDT.csv").
+func listRecordedPairs(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != jsonLinesExtension && ext != csvExtension) {
+			continue
+		}
+		pairs = append(pairs, strings.TrimSuffix(e.Name(), ext))
+	}
+
+	return pairs, nil
+}
+
+// readRecordedTicks reads the recorded ticks of a pair from the directory,
+// keeping only the ticks of the given kind, sorted by time.
+func readRecordedTicks(directory, pair string, kind tick.Kind) ([]tick.Tick, error) {
+	var ticks []tick.Tick
+	var err error
+	switch path := filepath.Join(directory, pair); {
+	case fileExists(path + jsonLinesExtension):
+		ticks, err = readJSONLinesFile(path + jsonLinesExtension)
+	case fileExists(path + csvExtension):
+		ticks, err = readCSVFile(path + csvExtension)
+	default:
+		return nil, fmt.Errorf("%w for pair %q in %q", ErrNoRecord, pair, directory)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter on the kind of ticks
+	filtered := make([]tick.Tick, 0, len(ticks))
+	for _, t := range ticks {
+		if t.Kind.OrDefault() == kind.OrDefault() {
+			filtered = append(filtered, t)
+		}
+	}
+
+	// Sort ticks by time, keeping the recorded order for same times
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Time.Before(filtered[j].Time)
+	})
+
+	return filtered, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// readJSONLinesFile reads ticks from a file containing one JSON tick per line.
+func readJSONLinesFile(path string) ([]tick.Tick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readJSONLines(f)
+}
+
+func readJSONLines(r io.Reader) ([]tick.Tick, error) {
+	ticks := make([]tick.Tick, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var t tick.Tick
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		reconcileExact(&t)
+		ticks = append(ticks, t)
+	}
+
+	return ticks, scanner.Err()
+}
+
+// reconcileExact fills the missing exact or float values of a tick, as a
+// recorded tick may contain only one of them. Exact values take precedence.
+func reconcileExact(t *tick.Tick) {
+	fields := []struct {
+		float *float64
+		exact *decimal.Decimal
+	}{
+		{&t.Price, &t.Exact.Price},
+		{&t.Bid, &t.Exact.Bid},
+		{&t.Ask, &t.Exact.Ask},
+		{&t.BidQty, &t.Exact.BidQty},
+		{&t.AskQty, &t.Exact.AskQty},
+		{&t.Qty, &t.Exact.Qty},
+	}
+	for _, f := range fields {
+		if f.exact.IsZero() && *f.float != 0 {
+			*f.exact = decimal.NewFromFloat(*f.float)
+		}
+		*f.float = f.exact.InexactFloat64()
+	}
+}
+
+// readCSVFile reads ticks from a CSV file with a header whose columns are
+// named after the tick JSON fields (i.e. "time,pair,price,bid,ask").
+func readCSVFile(path string) ([]tick.Tick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readCSV(f)
+}
+
+func readCSV(r io.Reader) ([]tick.Tick, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []tick.Tick{}, nil
+	}
+
+	header := records[0]
+	ticks := make([]tick.Tick, 0, len(records)-1)
+	for i, record := range records[1:] {
+		t, err := csvRecordToTick(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		ticks = append(ticks, t)
+	}
+
+	return ticks, nil
+}
+
+func csvRecordToTick(header, record []string) (tick.Tick, error) {
+	var t tick.Tick
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		if err := setCSVField(&t, column, value); err != nil {
+			return tick.Tick{}, fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+
+	t.Price, t.Bid, t.Ask, t.BidQty, t.AskQty, t.Qty = t.Exact.Float()
+	return t, nil
+}
+
+func setCSVField(t *tick.Tick, column, value string) (err error) {
+	switch column {
+	case "time":
+		t.Time, err = time.Parse(time.RFC3339Nano, value)
+	case "kind":
+		t.Kind = tick.Kind(value)
+	case "pair":
+		t.Pair = value
+	case "exchange":
+		t.Exchange = value
+	case "side":
+		t.Side = tick.Side(value)
+	case "price":
+		t.Exact.Price, err = decimal.NewFromString(value)
+	case "bid":
+		t.Exact.Bid, err = decimal.NewFromString(value)
+	case "ask":
+		t.Exact.Ask, err = decimal.NewFromString(value)
+	case "bid_qty":
+		t.Exact.BidQty, err = decimal.NewFromString(value)
+	case "ask_qty":
+		t.Exact.AskQty, err = decimal.NewFromString(value)
+	case "qty":
+		t.Exact.Qty, err = decimal.NewFromString(value)
+	}
+	return err
+}
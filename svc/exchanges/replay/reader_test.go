@@ -0,0 +1,99 @@
+//go:build unit
+// +build unit
+
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReaderSuite(t *testing.T) {
+	suite.Run(t, new(ReaderSuite))
+}
+
+type ReaderSuite struct {
+	suite.Suite
+	directory string
+}
+
+func (suite *ReaderSuite) SetupTest() {
+	suite.directory = suite.T().TempDir()
+
+	jsonl := `{"time":"2024-01-01T00:00:02Z","kind":"book","pair":"BTC-USDT","exact":{"bid":"99","ask":"101"}}
+{"time":"2024-01-01T00:00:01Z","kind":"book","pair":"BTC-USDT","exact":{"bid":"98","ask":"100"}}
+{"time":"2024-01-01T00:00:01Z","kind":"trade","pair":"BTC-USDT","exact":{"price":"100","qty":"1"}}
+`
+	floatJSONL := `{"time":"2024-01-01T00:00:01Z","kind":"book","pair":"SOL-USDT","bid":10.5,"ask":10.75,"bid_qty":2}
+{"time":"2024-01-01T00:00:02Z","kind":"book","pair":"SOL-USDT","bid":1,"ask":2,"exact":{"bid":"10.6","ask":"10.8"}}
+`
+	csv := "time,kind,bid,ask,price\n" +
+		"2024-01-01T00:00:01Z,book,0.00000122,0.00000124,0.00000123\n"
+
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.directory, "BTC-USDT.jsonl"), []byte(jsonl), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.directory, "SOL-USDT.jsonl"), []byte(floatJSONL), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.directory, "SHIB-USDT.csv"), []byte(csv), 0o600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.directory, "README.md"), []byte("ignored"), 0o600))
+}
+
+func (suite *ReaderSuite) TestListRecordedPairs() {
+	pairs, err := listRecordedPairs(suite.directory)
+	suite.Require().NoError(err)
+	suite.Require().ElementsMatch([]string{"BTC-USDT", "SHIB-USDT", "SOL-USDT"}, pairs)
+}
+
+func (suite *ReaderSuite) TestReadJSONLines() {
+	ticks, err := readRecordedTicks(suite.directory, "BTC-USDT", tick.KindBook)
+	suite.Require().NoError(err)
+	suite.Require().Len(ticks, 2)
+	suite.Require().Equal(time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), ticks[0].Time)
+	suite.Require().Equal("98", ticks[0].Exact.Bid.String())
+	suite.Require().Equal(98.0, ticks[0].Bid)
+	suite.Require().Equal(100.0, ticks[0].Ask)
+
+	ticks, err = readRecordedTicks(suite.directory, "BTC-USDT", tick.KindTrade)
+	suite.Require().NoError(err)
+	suite.Require().Len(ticks, 1)
+}
+
+func (suite *ReaderSuite) TestReadJSONLinesWithFloats() {
+	ticks, err := readRecordedTicks(suite.directory, "SOL-USDT", tick.KindBook)
+	suite.Require().NoError(err)
+	suite.Require().Len(ticks, 2)
+
+	// Exact values are filled from the floats
+	suite.Require().Equal("10.5", ticks[0].Exact.Bid.String())
+	suite.Require().Equal("10.75", ticks[0].Exact.Ask.String())
+	suite.Require().Equal("2", ticks[0].Exact.BidQty.String())
+	suite.Require().True(ticks[0].Exact.AskQty.IsZero())
+	suite.Require().Equal(10.5, ticks[0].Bid)
+
+	// Exact values take precedence over the floats
+	suite.Require().Equal("10.6", ticks[1].Exact.Bid.String())
+	suite.Require().Equal(10.6, ticks[1].Bid)
+	suite.Require().Equal(10.8, ticks[1].Ask)
+}
+
+func (suite *ReaderSuite) TestReadCSV() {
+	ticks, err := readRecordedTicks(suite.directory, "SHIB-USDT", tick.KindBook)
+	suite.Require().NoError(err)
+	suite.Require().Len(ticks, 1)
+	suite.Require().Equal("0.00000123", ticks[0].Exact.Price.String())
+	suite.Require().Equal(0.00000122, ticks[0].Bid)
+}
+
+func (suite *ReaderSuite) TestNoRecord() {
+	_, err := readRecordedTicks(suite.directory, "ETH-USDT", tick.KindBook)
+	suite.Require().ErrorIs(err, ErrNoRecord)
+}
+
+func (suite *ReaderSuite) TestReadCSVInvalidValue() {
+	_, err := readCSV(strings.NewReader("time,price\n2024-01-01T00:00:01Z,abc\n"))
+	suite.Require().Error(err)
+}
@@ -0,0 +1,119 @@
+package simulated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"go.temporal.io/sdk/activity"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Config is the configuration of the simulated exchange.
+type Config struct {
+	// Seed is the seed used to generate the random walk of each pair.
+	Seed uint64
+	// Rate is the interval between two generated ticks.
+	Rate time.Duration
+	// Pairs is the list of pairs available on the exchange.
+	Pairs []string
+	// Volatility is the standard deviation of the relative price change between two ticks.
+	Volatility float64
+	// InitialPrice is the price of each pair at the start of the random walk.
+	InitialPrice float64
+}
+
+// Validate checks that the configuration is valid.
+func (c Config) Validate() error {
+	if c.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if len(c.Pairs) == 0 {
+		return errors.New("at least one pair must be provided")
+	}
+	if c.Volatility < 0 {
+		return errors.New("volatility cannot be negative")
+	}
+	if c.InitialPrice <= 0 {
+		return errors.New("initial price must be positive")
+	}
+	return nil
+}
+
+// Activities is the struct that will handle the simulated exchange.
+type Activities struct {
+	temporal temporalclient.Client
+	config   Config
+}
+
+// New will create a new simulated exchange.
+func New(temporal temporalclient.Client, config Config) (*Activities, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Activities{
+		temporal: temporal,
+		config:   config,
+	}, nil
+}
+
+// Name will return the name of the exchange.
+func (a *Activities) Name() string {
+	return ExchangeName
+}
+
+// LocalPairs will return the configured pairs as the simulated exchange is
+// unknown to the exchanges service.
+func (a *Activities) LocalPairs(_ string) ([]string, bool) {
+	return a.config.Pairs, true
+}
+
+// Register will register the exchange.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+}
+
+// ListenSymbolActivity will generate ticks for the symbol until the context is done.
+func (a *Activities) ListenSymbolActivity(
+	ctx context.Context,
+	params exchanges.ListenSymbolParams,
+) (exchanges.ListenSymbolResults, error) {
+	if !slices.Contains(a.config.Pairs, params.Symbol) {
+		return exchanges.ListenSymbolResults{}, fmt.Errorf(
+			"pair %q doesn't exist for exchange %q", params.Symbol, ExchangeName)
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return exchanges.ListenSymbolResults{}, err
+	}
+
+	// Start heartbeat on activity
+	temporal.AsyncActivityHeartbeat(ctx, 300*time.Millisecond)
+
+	// Generate ticks at the configured rate
+	gen := newGenerator(a.config.Seed, params.Symbol, params.Kind, a.config.InitialPrice, a.config.Volatility)
+	ticker := time.NewTicker(a.config.Rate)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return exchanges.ListenSymbolResults{}, nil
+		case now := <-ticker.C:
+			t := gen.Next(now.UTC())
+			if err := signaling.SignalNewTick(ctx, a.temporal, params.ParentWorkflowID, t); err != nil {
+				if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+					return exchanges.ListenSymbolResults{}, nil
+				}
+				return exchanges.ListenSymbolResults{}, err
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build unit
+// +build unit
+
+package simulated
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/temporal"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/exchanges/internal/signaling"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/testsuite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSimulatedSuite(t *testing.T) {
+	suite.Run(t, new(SimulatedSuite))
+}
+
+type SimulatedSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func (suite *SimulatedSuite) TestParentNotRunning() {
+	client := temporal.NewMockClient(gomock.NewController(suite.T()))
+	acts, err := New(client, Config{
+		Rate:         time.Millisecond,
+		Pairs:        []string{"BTC-USDT"},
+		Volatility:   0.001,
+		InitialPrice: 100,
+	})
+	suite.Require().NoError(err)
+
+	// The sentry is gone after the first tick
+	gomock.InOrder(
+		client.EXPECT().
+			SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+			Return(nil),
+		client.EXPECT().
+			SignalWorkflow(gomock.Any(), "parent", "", signals.NewTickReceivedSignalName, gomock.Any()).
+			Return(serviceerror.NewNotFound("workflow execution already completed")),
+	)
+	client.EXPECT().
+		DescribeWorkflowExecution(gomock.Any(), "parent", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	// The generation stops
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(acts.ListenSymbolActivity)
+	_, err = env.ExecuteActivity(acts.ListenSymbolActivity, exchanges.ListenSymbolParams{
+		ParentWorkflowID: "parent",
+		Exchange:         ExchangeName,
+		Symbol:           "BTC-USDT",
+		Kind:             tick.KindTrade,
+	})
+	suite.Require().ErrorContains(err, signaling.ErrParentNotRunning.Error())
+}
@@ -0,0 +1,68 @@
+package simulated
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// pricePrecision is the number of decimals kept on generated prices.
+	pricePrecision = 8
+	// qtyPrecision is the number of decimals kept on generated quantities.
+	qtyPrecision = 4
+	// spreadRatio is the ratio of the price used as spread between bid and ask.
+	spreadRatio = 0.0001
+)
+
+// generator generates deterministic ticks following a random walk.
+type generator struct {
+	pair       string
+	kind       tick.Kind
+	price      float64
+	volatility float64
+	rand       *rand.Rand
+}
+
+// newGenerator creates a new generator for the given pair. The generated
+// ticks only depend on the seed and the pair.
+func newGenerator(seed uint64, pair string, kind tick.Kind, initialPrice, volatility float64) *generator {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(pair))
+
+	return &generator{
+		pair:       pair,
+		kind:       kind.OrDefault(),
+		price:      initialPrice,
+		volatility: volatility,
+		rand:       rand.New(rand.NewPCG(seed, h.Sum64())),
+	}
+}
+
+// Next generates the next tick at the given time.
+func (g *generator) Next(t time.Time) tick.Tick {
+	// Move the price following a geometric random walk
+	g.price *= math.Exp(g.volatility * g.rand.NormFloat64())
+	price := decimal.NewFromFloat(g.price).Round(pricePrecision)
+	qty := decimal.NewFromFloat(g.rand.Float64() * 10).Round(qtyPrecision)
+
+	// Generate a trade tick
+	if g.kind == tick.KindTrade {
+		side := tick.SideBuy
+		if g.rand.IntN(2) == 0 {
+			side = tick.SideSell
+		}
+		return tick.FromExactTrade(ExchangeName, g.pair, t, price, qty, side)
+	}
+
+	// Generate a book tick
+	halfSpread := decimal.NewFromFloat(g.price * spreadRatio / 2).Round(pricePrecision)
+	askQty := decimal.NewFromFloat(g.rand.Float64() * 10).Round(qtyPrecision)
+	return tick.FromExactBook(ExchangeName, g.pair, t,
+		price.Sub(halfSpread), qty,
+		price.Add(halfSpread), askQty)
+}
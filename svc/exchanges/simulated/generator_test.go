@@ -0,0 +1,50 @@
+//go:build unit
+// +build unit
+
+package simulated
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestGeneratorSuite(t *testing.T) {
+	suite.Run(t, new(GeneratorSuite))
+}
+
+type GeneratorSuite struct {
+	suite.Suite
+}
+
+func (suite *GeneratorSuite) TestDeterministic() {
+	g1 := newGenerator(42, "BTC-USDT", tick.KindBook, 100, 0.01)
+	g2 := newGenerator(42, "BTC-USDT", tick.KindBook, 100, 0.01)
+
+	t := time.Unix(60, 0).UTC()
+	for i := 0; i < 100; i++ {
+		t1, t2 := g1.Next(t), g2.Next(t)
+		suite.Require().True(t1.Exact.Equal(t2.Exact))
+		suite.Require().Greater(t1.Ask, t1.Bid)
+		suite.Require().Equal(tick.KindBook, t1.Kind)
+	}
+}
+
+func (suite *GeneratorSuite) TestDifferentPairs() {
+	g1 := newGenerator(42, "BTC-USDT", tick.KindBook, 100, 0.01)
+	g2 := newGenerator(42, "ETH-USDT", tick.KindBook, 100, 0.01)
+
+	t := time.Unix(60, 0).UTC()
+	suite.Require().NotEqual(g1.Next(t).Price, g2.Next(t).Price)
+}
+
+func (suite *GeneratorSuite) TestTrades() {
+	g := newGenerator(42, "BTC-USDT", tick.KindTrade, 100, 0.01)
+
+	t := g.Next(time.Unix(60, 0).UTC())
+	suite.Require().Equal(tick.KindTrade, t.Kind)
+	suite.Require().Contains([]tick.Side{tick.SideBuy, tick.SideSell}, t.Side)
+	suite.Require().Positive(t.Price)
+}
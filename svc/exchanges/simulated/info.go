@@ -0,0 +1,6 @@
+package simulated
+
+const (
+	// ExchangeName is the name of the exchange.
+	ExchangeName = "simulated"
+)
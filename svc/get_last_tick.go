@@ -0,0 +1,131 @@
+package svc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/internal/activities"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"go.temporal.io/sdk/workflow"
+)
+
+// lastTickFetchTimeout is the maximum time to wait for a tick from the
+// exchange when there is no running sentry.
+const lastTickFetchTimeout = 30 * time.Second
+
+// GetLastTickWorkflow returns the last known tick of an exchange and pair.
+// It first asks the running sentry, then falls back on the last recorded tick,
+// both only if they are recent enough, and finally fetches a tick from the
+// exchange.
+func (wf *workflows) GetLastTickWorkflow(
+	ctx workflow.Context,
+	params api.GetLastTickWorkflowParams,
+) (api.GetLastTickWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Requested last tick",
+		"exchange", params.Exchange,
+		"pair", params.Pair,
+		"kind", params.Kind)
+
+	// Check and fix params
+	if params.Exchange == "" {
+		return api.GetLastTickWorkflowResults{}, errors.New("exchange must be provided")
+	}
+	if params.Pair == "" {
+		return api.GetLastTickWorkflowResults{}, errors.New("pair must be provided")
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return api.GetLastTickWorkflowResults{}, err
+	}
+	params.Kind = params.Kind.OrDefault()
+	if params.MaxAge == 0 {
+		params.MaxAge = api.DefaultLastTickMaxAge
+	}
+
+	// Ask the running sentry, and only use its tick if it is recent enough
+	queryRes, err := activities.ExecuteQueryLastTick(ctx, activities.QueryLastTickActivityParams{
+		WorkflowID: sentryWorkflowName(params.Exchange, params.Pair, params.Kind),
+	})
+	if err != nil {
+		return api.GetLastTickWorkflowResults{}, err
+	} else if queryRes.Tick != nil && workflow.Now(ctx).Sub(queryRes.Tick.Time) <= params.MaxAge {
+		return api.GetLastTickWorkflowResults{
+			Tick:   *queryRes.Tick,
+			Source: api.LastTickSourceListener,
+		}, nil
+	}
+
+	// Get the last recorded tick
+	var dbRes db.ReadLastTickActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.ReadLastTickActivity, db.ReadLastTickActivityParams{
+			Exchange: params.Exchange,
+			Pair:     params.Pair,
+			Kind:     params.Kind,
+		}).Get(ctx, &dbRes)
+	if err != nil {
+		return api.GetLastTickWorkflowResults{}, err
+	} else if dbRes.Tick != nil && workflow.Now(ctx).Sub(dbRes.Tick.Time) <= params.MaxAge {
+		return api.GetLastTickWorkflowResults{
+			Tick:   *dbRes.Tick,
+			Source: api.LastTickSourceDatabase,
+		}, nil
+	}
+
+	// Fetch a tick from the exchange
+	if err := wf.checkPairAndExchange(ctx, params.Pair, params.Exchange); err != nil {
+		return api.GetLastTickWorkflowResults{}, err
+	}
+	t, err := wf.fetchTickFromExchange(ctx, params)
+	if err != nil {
+		return api.GetLastTickWorkflowResults{}, err
+	}
+
+	return api.GetLastTickWorkflowResults{
+		Tick:   t,
+		Source: api.LastTickSourceExchange,
+	}, nil
+}
+
+// fetchTickFromExchange listens to the exchange from the current workflow
+// until the first tick is received.
+func (wf *workflows) fetchTickFromExchange(
+	ctx workflow.Context,
+	params api.GetLastTickWorkflowParams,
+) (tick.Tick, error) {
+	future, cancelListening := wf.sentryStartListeningActivity(ctx, ticksSentryWorkflowParams{
+		Exchange: params.Exchange,
+		Symbol:   params.Pair,
+		Kind:     params.Kind,
+	})
+	defer cancelListening()
+
+	var t tick.Tick
+	var received bool
+	var err error
+	newTickReceivedSignalChannel := workflow.GetSignalChannel(ctx, signals.NewTickReceivedSignalName)
+	timer := workflow.NewTimer(ctx, lastTickFetchTimeout)
+	for !received && err == nil {
+		workflow.NewSelector(ctx).
+			AddReceive(newTickReceivedSignalChannel, func(c workflow.ReceiveChannel, _ bool) {
+				c.Receive(ctx, &t)
+				received = !t.IsGap()
+			}).
+			AddFuture(future, func(f workflow.Future) {
+				if err = f.Get(ctx, nil); err == nil {
+					err = errors.New("exchange listening stopped before receiving a tick")
+				}
+			}).
+			AddFuture(timer, func(_ workflow.Future) {
+				err = fmt.Errorf("no tick received from %q after %s", params.Exchange, lastTickFetchTimeout)
+			}).
+			Select(ctx)
+	}
+
+	return t, err
+}
@@ -0,0 +1,86 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/internal/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetLastTickSuite(t *testing.T) {
+	suite.Run(t, new(GetLastTickSuite))
+}
+
+type GetLastTickSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+	now time.Time
+}
+
+func (suite *GetLastTickSuite) SetupTest() {
+	ctrl := gomock.NewController(suite.T())
+	wf := &workflows{
+		db: db.NewMockDB(ctrl),
+	}
+
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.env = suite.NewTestWorkflowEnvironment()
+	suite.env.SetStartTime(suite.now)
+	suite.env.RegisterWorkflowWithOptions(wf.GetLastTickWorkflow, workflow.RegisterOptions{
+		Name: api.GetLastTickWorkflowName,
+	})
+	suite.env.RegisterActivity((&activities.Activities{}).QueryLastTickActivity)
+	suite.env.RegisterActivityWithOptions(wf.db.ReadLastTickActivity,
+		activity.RegisterOptions{Name: db.ReadLastTickActivityName})
+}
+
+func (suite *GetLastTickSuite) tickAged(age time.Duration) *tick.Tick {
+	t := tick.FromBook("exchange", "ETH-USDC", suite.now.Add(-age), 1, 1, 2, 1)
+	return &t
+}
+
+func (suite *GetLastTickSuite) getLastTick(sentryTick, dbTick *tick.Tick) api.GetLastTickWorkflowResults {
+	suite.env.OnActivity("QueryLastTickActivity", mock.Anything, mock.Anything).
+		Return(activities.QueryLastTickActivityResults{Tick: sentryTick}, nil)
+	suite.env.OnActivity(db.ReadLastTickActivityName, mock.Anything, mock.Anything).
+		Return(db.ReadLastTickActivityResults{Tick: dbTick}, nil)
+
+	suite.env.ExecuteWorkflow(api.GetLastTickWorkflowName, api.GetLastTickWorkflowParams{
+		Exchange: "exchange",
+		Pair:     "ETH-USDC",
+		MaxAge:   time.Minute,
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	var res api.GetLastTickWorkflowResults
+	suite.Require().NoError(suite.env.GetWorkflowResult(&res))
+	return res
+}
+
+func (suite *GetLastTickSuite) TestFromSentry() {
+	res := suite.getLastTick(suite.tickAged(time.Second), suite.tickAged(2*time.Second))
+	suite.Require().Equal(api.LastTickSourceListener, res.Source)
+	suite.Require().Equal(suite.now.Add(-time.Second), res.Tick.Time)
+}
+
+func (suite *GetLastTickSuite) TestTooOldFromSentry() {
+	// The sentry has not received any tick for longer than the maximum age
+	res := suite.getLastTick(suite.tickAged(2*time.Minute), suite.tickAged(30*time.Second))
+	suite.Require().Equal(api.LastTickSourceDatabase, res.Source)
+	suite.Require().Equal(suite.now.Add(-30*time.Second), res.Tick.Time)
+}
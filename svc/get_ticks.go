@@ -0,0 +1,98 @@
+package svc
+
+import (
+	"errors"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+// GetTicksWorkflow returns the recorded ticks of an exchange and pair.
+func (wf *workflows) GetTicksWorkflow(
+	ctx workflow.Context,
+	params api.GetTicksWorkflowParams,
+) (api.GetTicksWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Requested ticks",
+		"exchange", params.Exchange,
+		"pair", params.Pair,
+		"kind", params.Kind,
+		"start", params.Start,
+		"end", params.End,
+		"limit", params.Limit,
+		"cursor", params.Cursor)
+
+	// Check and fix params
+	params, after, err := validateGetTicksParams(ctx, params)
+	if err != nil {
+		return api.GetTicksWorkflowResults{}, err
+	}
+
+	// Read ticks from database
+	var dbRes db.ReadTicksActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.ReadTicksActivity, db.ReadTicksActivityParams{
+			Exchange: params.Exchange,
+			Pair:     params.Pair,
+			Kind:     params.Kind,
+			Start:    params.Start,
+			End:      params.End,
+			Limit:    params.Limit,
+			After:    after,
+		}).Get(ctx, &dbRes)
+	if err != nil {
+		return api.GetTicksWorkflowResults{}, err
+	}
+
+	// Set the next cursor if the page is full
+	res := api.GetTicksWorkflowResults{
+		Ticks: dbRes.Ticks,
+	}
+	if uint(len(dbRes.Ticks)) == params.Limit && dbRes.Last != nil {
+		res.NextCursor = dbRes.Last.String()
+	}
+
+	return res, nil
+}
+
+func validateGetTicksParams(
+	ctx workflow.Context,
+	params api.GetTicksWorkflowParams,
+) (api.GetTicksWorkflowParams, *db.Cursor, error) {
+	if params.Exchange == "" {
+		return params, nil, errors.New("exchange must be provided")
+	}
+	if params.Pair == "" {
+		return params, nil, errors.New("pair must be provided")
+	}
+	if err := params.Kind.Validate(); err != nil {
+		return params, nil, err
+	}
+	params.Kind = params.Kind.OrDefault()
+
+	// Set end to now if not set
+	if params.End.IsZero() {
+		params.End = workflow.Now(ctx)
+	}
+	if params.End.Before(params.Start) {
+		return params, nil, errors.New("end must be after start")
+	}
+
+	// Cap the limit
+	if params.Limit == 0 || params.Limit > api.GetTicksMaxLimit {
+		params.Limit = api.GetTicksMaxLimit
+	}
+
+	// Decode the cursor
+	if params.Cursor == "" {
+		return params, nil, nil
+	}
+	cursor, err := db.ParseCursor(params.Cursor)
+	if err != nil {
+		return params, nil, err
+	}
+
+	return params, &cursor, nil
+}
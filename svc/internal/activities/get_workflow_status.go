@@ -0,0 +1,54 @@
+package activities
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ExecuteGetWorkflowStatus is a wrapper for the GetWorkflowStatusActivity execution.
+func ExecuteGetWorkflowStatus(
+	ctx workflow.Context,
+	params GetWorkflowStatusActivityParams,
+) (GetWorkflowStatusActivityResults, error) {
+	var a *Activities
+	var res GetWorkflowStatusActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+			StartToCloseTimeout: time.Second * 10,
+		}),
+		a.GetWorkflowStatusActivity,
+		params).Get(ctx, &res)
+	return res, err
+}
+
+type (
+	// GetWorkflowStatusActivityParams is the params for the GetWorkflowStatusActivity activity.
+	GetWorkflowStatusActivityParams struct {
+		WorkflowID string
+	}
+
+	// GetWorkflowStatusActivityResults is the results from the GetWorkflowStatusActivity activity.
+	GetWorkflowStatusActivityResults struct {
+		// Status is the status of the last run of the workflow.
+		Status enums.WorkflowExecutionStatus
+	}
+)
+
+// GetWorkflowStatusActivity is an activity that will get the status of the
+// last run of a workflow.
+func (a *Activities) GetWorkflowStatusActivity(
+	ctx context.Context,
+	params GetWorkflowStatusActivityParams,
+) (GetWorkflowStatusActivityResults, error) {
+	desc, err := a.temporal.DescribeWorkflowExecution(ctx, params.WorkflowID, "")
+	if err != nil {
+		return GetWorkflowStatusActivityResults{}, err
+	}
+
+	return GetWorkflowStatusActivityResults{
+		Status: desc.GetWorkflowExecutionInfo().GetStatus(),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ExecuteListSentries is a wrapper for the ListSentriesActivity execution.
+func ExecuteListSentries(
+	ctx workflow.Context,
+	params ListSentriesActivityParams,
+) (ListSentriesActivityResults, error) {
+	var a *Activities
+	var res ListSentriesActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+			StartToCloseTimeout: 10 * time.Minute,
+			HeartbeatTimeout:    10 * time.Second,
+		}),
+		a.ListSentriesActivity,
+		params).Get(ctx, &res)
+	return res, err
+}
+
+type (
+	// ListSentriesActivityParams is the params for the ListSentriesActivity activity.
+	ListSentriesActivityParams struct {
+		// WorkflowType is the type of the sentry workflows to list.
+		WorkflowType string
+		// WorkflowIDPrefix restricts the queried sentries to the ones whose
+		// workflow ID starts with it, if set.
+		WorkflowIDPrefix string
+	}
+
+	// ListSentriesActivityResults is the results from the ListSentriesActivity activity.
+	ListSentriesActivityResults struct {
+		Sentries []api.SentryInfo
+	}
+)
+
+// ListSentriesActivity is an activity that will list the running sentry
+// workflows through the visibility API and query their information.
+func (a *Activities) ListSentriesActivity(
+	ctx context.Context,
+	params ListSentriesActivityParams,
+) (ListSentriesActivityResults, error) {
+	logger := activity.GetLogger(ctx)
+	query := fmt.Sprintf("WorkflowType = '%s' AND ExecutionStatus = 'Running'", params.WorkflowType)
+	if params.WorkflowIDPrefix != "" {
+		query += fmt.Sprintf(" AND WorkflowId STARTS_WITH '%s'", params.WorkflowIDPrefix)
+	}
+
+	sentries := make([]api.SentryInfo, 0)
+	var nextPageToken []byte
+	for {
+		// Get the next page of running sentries
+		resp, err := a.temporal.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Query:         query,
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return ListSentriesActivityResults{}, fmt.Errorf("listing sentries: %w", err)
+		}
+
+		// Query each sentry for its information
+		for _, exec := range resp.Executions {
+			id := exec.GetExecution().GetWorkflowId()
+
+			// Show progress as each query can take time
+			activity.RecordHeartbeat(ctx, len(sentries))
+
+			value, err := a.temporal.QueryWorkflow(ctx, id, exec.GetExecution().GetRunId(), signals.ListenersQueryName)
+			if err != nil {
+				// The sentry may have stopped since it has been listed
+				logger.Warn("Cannot query sentry", "workflow_id", id, "error", err)
+				continue
+			}
+
+			var info api.SentryInfo
+			if err := value.Get(&info); err != nil {
+				// Keep listing the other sentries
+				logger.Warn("Cannot decode sentry info", "workflow_id", id, "error", err)
+				continue
+			}
+			info.WorkflowID = id
+			info.StartTime = exec.GetStartTime().AsTime()
+			sentries = append(sentries, info)
+		}
+
+		activity.RecordHeartbeat(ctx, len(sentries))
+		nextPageToken = resp.NextPageToken
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+
+	return ListSentriesActivityResults{
+		Sentries: sentries,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ExecuteQueryLastTick is a wrapper for the QueryLastTickActivity execution.
+func ExecuteQueryLastTick(
+	ctx workflow.Context,
+	params QueryLastTickActivityParams,
+) (QueryLastTickActivityResults, error) {
+	var a *Activities
+	var res QueryLastTickActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+			StartToCloseTimeout: time.Second * 10,
+		}),
+		a.QueryLastTickActivity,
+		params).Get(ctx, &res)
+	return res, err
+}
+
+type (
+	// QueryLastTickActivityParams is the params for the QueryLastTickActivity activity.
+	QueryLastTickActivityParams struct {
+		WorkflowID string
+	}
+
+	// QueryLastTickActivityResults is the results from the QueryLastTickActivity activity.
+	QueryLastTickActivityResults struct {
+		// Tick is the last tick received by the sentry, nil if the sentry is
+		// not running or has not received any tick yet.
+		Tick *tick.Tick
+	}
+)
+
+// QueryLastTickActivity is an activity that will query the last tick received
+// by a sentry workflow, if it is running.
+func (a *Activities) QueryLastTickActivity(
+	ctx context.Context,
+	params QueryLastTickActivityParams,
+) (QueryLastTickActivityResults, error) {
+	res, err := a.temporal.QueryWorkflowWithOptions(ctx, &temporalclient.QueryWorkflowWithOptionsRequest{
+		WorkflowID:           params.WorkflowID,
+		QueryType:            signals.LastTickQueryName,
+		QueryRejectCondition: enums.QUERY_REJECT_CONDITION_NOT_OPEN,
+	})
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return QueryLastTickActivityResults{}, nil
+	} else if err != nil {
+		return QueryLastTickActivityResults{}, err
+	}
+
+	// Check that the sentry is still running
+	if res.QueryRejected != nil {
+		return QueryLastTickActivityResults{}, nil
+	}
+
+	var t *tick.Tick
+	if err := res.QueryResult.Get(&t); err != nil {
+		return QueryLastTickActivityResults{}, err
+	}
+
+	return QueryLastTickActivityResults{
+		Tick: t,
+	}, nil
+}
@@ -2,6 +2,7 @@ package signals
 
 import (
 	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
 	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/google/uuid"
 )
@@ -15,6 +16,8 @@ type (
 	RegisterToTicksListeningSignalParams struct {
 		RequesterID      uuid.UUID
 		CallbackWorkflow runtime.CallbackWorkflow
+		Signal           api.SignalTarget
+		Delivery         api.DeliveryOptions
 	}
 )
 
@@ -39,3 +42,11 @@ type (
 		Tick tick.Tick
 	}
 )
+
+// LastTickQueryName is the name of the query to get the last tick received
+// by a running sentry. It returns nil if no tick has been received yet.
+const LastTickQueryName = "LastTickQuery"
+
+// ListenersQueryName is the name of the query to get the information of a
+// running sentry, including its listeners, as an api.SentryInfo.
+const ListenersQueryName = "ListenersQuery"
@@ -0,0 +1,43 @@
+package svc
+
+import (
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/internal/activities"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ListSubscriptionsWorkflow returns the running sentries and their subscriptions.
+func (wf *workflows) ListSubscriptionsWorkflow(
+	ctx workflow.Context,
+	params api.ListSubscriptionsWorkflowParams,
+) (api.ListSubscriptionsWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Requested subscriptions list",
+		"exchange", params.Exchange,
+		"pair", params.Pair)
+
+	// List the running sentries
+	res, err := activities.ExecuteListSentries(ctx, activities.ListSentriesActivityParams{
+		WorkflowType:     ticksSentryWorkflowName,
+		WorkflowIDPrefix: sentryWorkflowIDPrefix(params.Exchange, params.Pair),
+	})
+	if err != nil {
+		return api.ListSubscriptionsWorkflowResults{}, err
+	}
+
+	// Filter them, as the workflow ID prefix can match other exchanges or pairs
+	sentries := make([]api.SentryInfo, 0, len(res.Sentries))
+	for _, s := range res.Sentries {
+		if params.Exchange != "" && s.Exchange != params.Exchange {
+			continue
+		}
+		if params.Pair != "" && s.Pair != params.Pair {
+			continue
+		}
+		sentries = append(sentries, s)
+	}
+
+	return api.ListSubscriptionsWorkflowResults{
+		Sentries: sentries,
+	}, nil
+}
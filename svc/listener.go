@@ -0,0 +1,656 @@
+package svc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/internal/activities"
+	"github.com/google/uuid"
+	"github.com/iancoleman/strcase"
+	"github.com/shopspring/decimal"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ErrDeliveryInProgress is the error when the ticks are already being
+// delivered by another execution, whose success is not known yet.
+var ErrDeliveryInProgress = errors.New("delivery in progress")
+
+// listener is a callback workflow registered on a sentry, with the ticks
+// waiting to be delivered to it.
+type listener struct {
+	requesterID  uuid.UUID
+	callback     runtime.CallbackWorkflow
+	signal       api.SignalTarget
+	delivery     api.DeliveryOptions
+	registeredAt time.Time
+
+	// queue is the ticks waiting to be delivered.
+	queue []tick.Tick
+	// sampled is the last tick of the current sample interval, if any.
+	sampled *tick.Tick
+	// reference is the last tick enqueued for delivery, from which the price
+	// change is measured, if any.
+	reference *tick.Tick
+	// dropped is the count of ticks dropped since the last delivery.
+	dropped uint64
+
+	delivered    uint64
+	failed       uint64
+	totalDropped uint64
+
+	// consecutiveFailures is the count of deliveries that failed in a row.
+	consecutiveFailures uint
+	// pausedUntil is the end of the pause of the deliveries, if any.
+	pausedUntil time.Time
+
+	// sending is true while a tick is being sent to the callback.
+	sending bool
+	// inFlight is the ticks being delivered, if any.
+	inFlight []tick.Tick
+	// stopped is true when the listener should not deliver ticks anymore.
+	stopped bool
+	// ended is true when the service has ended the subscription.
+	ended bool
+}
+
+func newListener(
+	requesterID uuid.UUID,
+	callback runtime.CallbackWorkflow,
+	signal api.SignalTarget,
+	delivery api.DeliveryOptions,
+	registeredAt time.Time,
+) *listener {
+	return &listener{
+		requesterID:  requesterID,
+		callback:     callback,
+		signal:       signal.WithDefaults(),
+		delivery:     delivery.WithDefaults(),
+		registeredAt: registeredAt,
+	}
+}
+
+// Push adds a tick to the ticks waiting for delivery if the price moved
+// enough. If the listener is sampled, the tick replaces the previous one of
+// the current interval. Gap ticks are only added if the listener asked for them.
+func (l *listener) Push(t tick.Tick) {
+	if l.stopped {
+		return
+	}
+
+	// Filter the ticks where the price did not move enough, the tick following
+	// a gap being always kept
+	if t.IsGap() {
+		l.reference = nil
+		if !l.delivery.DeliverGaps {
+			return
+		}
+	} else if !l.priceMoved(t) {
+		return
+	}
+
+	if l.delivery.SampleInterval > 0 && !t.IsGap() {
+		l.sampled = &t
+		return
+	}
+
+	l.enqueue(t)
+}
+
+// priceMoved returns true if the tick price moved enough since the reference
+// tick, or if there is no threshold or reference.
+func (l *listener) priceMoved(t tick.Tick) bool {
+	absolute, bps := l.delivery.MinPriceChange, l.delivery.MinPriceChangeBps
+	if l.reference == nil || (absolute == 0 && bps == 0) {
+		return true
+	}
+
+	reference := l.reference.Exact.Price
+	change := t.Exact.Price.Sub(reference).Abs()
+	if absolute > 0 && change.GreaterThan(decimal.NewFromFloat(absolute)) {
+		return true
+	}
+	if bps > 0 && !reference.IsZero() &&
+		change.Div(reference.Abs()).Mul(decimal.NewFromInt(10000)).GreaterThan(decimal.NewFromFloat(bps)) {
+		return true
+	}
+
+	return false
+}
+
+// enqueue adds a tick to the queue, applying the overflow policy if there is
+// no room left for it. The tick becomes the price change reference if it is kept.
+func (l *listener) enqueue(t tick.Tick) {
+	// Add the tick if there is room for it or if the policy is to block, in
+	// which case the listener routine makes room for it, otherwise apply the
+	// overflow policy
+	if uint(len(l.queue)) < l.delivery.BufferSize || l.delivery.OverflowPolicy == api.OverflowPolicyBlock {
+		l.queue = append(l.queue, t)
+	} else {
+		l.dropped++
+		l.totalDropped++
+
+		switch l.delivery.OverflowPolicy {
+		case api.OverflowPolicyDropNewest:
+			return
+		case api.OverflowPolicyConflate:
+			l.queue[len(l.queue)-1] = t
+		default:
+			l.queue = append(l.queue[1:], t)
+		}
+	}
+
+	if !t.IsGap() {
+		l.reference = &t
+	}
+}
+
+// Stop stops the delivery of ticks to the listener, once the current one is done.
+func (l *listener) Stop() {
+	l.stopped = true
+}
+
+// Info returns the public information of the listener.
+func (l *listener) Info() api.Subscription {
+	return api.Subscription{
+		RequesterID:  l.requesterID,
+		CallbackName: l.callback.Name,
+		TaskQueue:    l.callback.TaskQueueName,
+		Signal:       l.signal,
+		RegisteredAt: l.registeredAt,
+		Delivery:     l.delivery,
+		Delivered:    l.delivered,
+		Failed:       l.failed,
+		Dropped:      l.totalDropped,
+		Queued:       len(l.queue),
+
+		ConsecutiveFailures: l.consecutiveFailures,
+		PausedUntil:         l.pausedUntil,
+	}
+}
+
+// sampleRoutine enqueues the last tick of each sample interval, starting an
+// interval when a tick is received, until the listener is stopped.
+func (l *listener) sampleRoutine(ctx workflow.Context) {
+	for {
+		// Wait for a tick to start the interval
+		_ = workflow.Await(ctx, func() bool {
+			return l.sampled != nil || l.stopped
+		})
+		if l.stopped {
+			return
+		}
+
+		// Wait for the end of the interval and enqueue its last tick
+		if err := workflow.Sleep(ctx, l.delivery.SampleInterval); err != nil || l.stopped {
+			return
+		}
+		l.enqueue(*l.sampled)
+		l.sampled = nil
+	}
+}
+
+// blockRoutine waits for the listener to make room for the ticks queued over
+// its buffer size with the OverflowPolicyBlock policy, until it is stopped.
+// The oldest ticks are dropped if there is still no room after MaxBlockDuration,
+// so that the other listeners of the sentry are never waiting for this one.
+func (l *listener) blockRoutine(ctx workflow.Context) {
+	overflowing := func() bool {
+		return uint(len(l.queue)) > l.delivery.BufferSize
+	}
+
+	for {
+		// Wait for ticks over the buffer size
+		_ = workflow.Await(ctx, func() bool {
+			return overflowing() || l.stopped
+		})
+		if l.stopped {
+			return
+		}
+
+		// Wait for the deliveries to make room, for a limited time
+		_, _ = workflow.AwaitWithTimeout(ctx, api.MaxBlockDuration, func() bool {
+			return !overflowing() || l.stopped
+		})
+		if l.stopped {
+			return
+		}
+		if overflowing() {
+			count := len(l.queue) - int(l.delivery.BufferSize)
+			l.queue = l.queue[count:]
+			l.dropped += uint64(count)
+			l.totalDropped += uint64(count)
+		}
+	}
+}
+
+// next waits for the next ticks to deliver and removes them from the queue,
+// with the count of ticks dropped since the last delivery. It returns a single
+// tick, or a batch if batching is enabled: the batch is taken once it is full
+// or when its first tick has waited for the maximum latency. It returns false
+// if the listener has been stopped in the meantime.
+func (l *listener) next(ctx workflow.Context) ([]tick.Tick, uint64, bool) {
+	_ = workflow.Await(ctx, func() bool {
+		return len(l.queue) > 0 || l.stopped
+	})
+	if l.stopped {
+		return nil, 0, false
+	}
+
+	size := uint(1)
+	if batch := l.delivery.Batch; batch.Enabled() {
+		_, _ = workflow.AwaitWithTimeout(ctx, batch.MaxLatency, func() bool {
+			return uint(len(l.queue)) >= batch.MaxSize || l.stopped
+		})
+		if l.stopped {
+			return nil, 0, false
+		}
+		size = min(batch.MaxSize, uint(len(l.queue)))
+	}
+
+	ticks, dropped := l.queue[:size:size], l.dropped
+	l.queue, l.dropped = l.queue[size:], 0
+	return ticks, dropped, true
+}
+
+// routine returns the workflow routine that sends the ticks to the listener
+// until it is stopped or has timed out.
+func (l *listener) routine(listeners map[string]*listener) func(ctx workflow.Context) {
+	return func(ctx workflow.Context) {
+		logger := workflow.GetLogger(ctx)
+
+		// Sample the ticks if requested
+		if l.delivery.SampleInterval > 0 {
+			workflow.Go(ctx, l.sampleRoutine)
+		}
+
+		// Make room for the ticks over the buffer size if blocking
+		if l.delivery.OverflowPolicy == api.OverflowPolicyBlock {
+			workflow.Go(ctx, l.blockRoutine)
+		}
+
+		for {
+			// Wait for the end of the pause and the next ticks
+			if !l.waitPause(ctx) {
+				return
+			}
+			ticks, dropped, ok := l.next(ctx)
+			if !ok {
+				return
+			}
+
+			// Send ticks to callback, and record them if it fails
+			l.sending, l.inFlight = true, ticks
+			err := l.deliver(ctx, ticks, dropped)
+			if err != nil {
+				l.recordDeadLetter(ctx, ticks, err)
+			}
+			l.sending, l.inFlight = false, nil
+			if err == nil {
+				l.delivered += uint64(len(ticks))
+				l.consecutiveFailures = 0
+				continue
+			}
+
+			l.failed += uint64(len(ticks))
+			l.consecutiveFailures++
+			if l.stopped {
+				return
+			}
+
+			// Apply the failure policy and end the subscription if needed
+			if reason, end := l.handleFailure(ctx, err); end {
+				logger.Debug("Removing listener", "callback", l.callback.Name, "reason", reason)
+				l.end(ctx, listeners, reason, err, ticks)
+				return
+			}
+		}
+	}
+}
+
+// waitPause waits for the end of the pause of the deliveries, if any. It
+// returns false if the listener has been stopped in the meantime.
+func (l *listener) waitPause(ctx workflow.Context) bool {
+	if d := l.pausedUntil.Sub(workflow.Now(ctx)); d > 0 {
+		_, _ = workflow.AwaitWithTimeout(ctx, d, func() bool {
+			return l.stopped
+		})
+	}
+	if l.stopped {
+		return false
+	}
+
+	l.pausedUntil = time.Time{}
+	return true
+}
+
+// handleFailure applies the failure policy after a failed delivery, and
+// returns the reason to end the subscription if it should be ended.
+func (l *listener) handleFailure(ctx workflow.Context, err error) (api.SubscriptionEndReason, bool) {
+	logger := workflow.GetLogger(ctx)
+
+	var timeoutErr *temporal.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		logger.Debug("Listener has timed out, exiting", "callback", l.callback.Name)
+		return api.SubscriptionEndReasonTimeout, true
+	}
+
+	var unknownErr *temporal.UnknownExternalWorkflowExecutionError
+	if errors.As(err, &unknownErr) {
+		logger.Debug("Listener workflow does not exist anymore, exiting", "workflow_id", l.signal.WorkflowID)
+		return api.SubscriptionEndReasonTargetNotFound, true
+	}
+
+	// Continue if the listener has not failed too many times in a row
+	policy := l.delivery.Failures
+	if policy.MaxConsecutiveFailures == 0 || l.consecutiveFailures < policy.MaxConsecutiveFailures {
+		logger.Error("Listener has errored, continuing", "error", err, "callback", l.callback.Name,
+			"consecutive_failures", l.consecutiveFailures)
+		return "", false
+	}
+
+	// Otherwise pause or evict the listener
+	if policy.Action == api.FailureActionPause {
+		logger.Warn("Listener has failed too many times, pausing", "error", err, "callback", l.callback.Name,
+			"pause", policy.PauseDuration)
+		l.pausedUntil = workflow.Now(ctx).Add(policy.PauseDuration)
+		l.consecutiveFailures = 0
+		return "", false
+	}
+
+	logger.Warn("Listener has failed too many times, evicting", "error", err, "callback", l.callback.Name)
+	return api.SubscriptionEndReasonFailures, true
+}
+
+// end removes the listener from the sentry as its subscription has been ended,
+// unless it has been registered again in the meantime, and notifies the
+// requester. The listener is kept until the notification is sent, so that the
+// sentry does not exit before.
+func (l *listener) end(
+	ctx workflow.Context,
+	listeners map[string]*listener,
+	reason api.SubscriptionEndReason,
+	err error,
+	ticks []tick.Tick,
+) {
+	l.Stop()
+	l.ended = true
+
+	if l.delivery.EndedCallback.Name != "" {
+		params := api.SubscriptionEndedCallbackWorkflowParams{
+			RequesterID: l.requesterID,
+			Exchange:    ticks[0].Exchange,
+			Pair:        ticks[0].Pair,
+			Kind:        ticksKind(ticks),
+			Reason:      reason,
+			Error:       err.Error(),
+		}
+
+		l.sending = true
+		if notifyErr := notifySubscriptionEnded(ctx, l.delivery.EndedCallback, params); notifyErr != nil {
+			workflow.GetLogger(ctx).Error("Could not notify the end of the subscription",
+				"error", notifyErr, "callback", l.delivery.EndedCallback.Name)
+		}
+		l.sending = false
+	}
+
+	if listeners[l.requesterID.String()] == l {
+		delete(listeners, l.requesterID.String())
+	}
+}
+
+const (
+	// deadLetterRetryInterval is the maximum interval between two attempts to
+	// record a dead letter.
+	deadLetterRetryInterval = time.Minute
+	// deadLetterRecordTimeout is the maximum time spent recording a dead
+	// letter, after which it is given up so that the listener is not stalled.
+	deadLetterRecordTimeout = 10 * time.Minute
+)
+
+// recordDeadLetter records a failed delivery, so that it can be inspected and
+// delivered again later. The recording is retried for a limited time, so that
+// the failed delivery is not lost while the database is briefly unavailable.
+func (l *listener) recordDeadLetter(ctx workflow.Context, ticks []tick.Tick, err error) {
+	// Signals are only sent once
+	attempts := 1
+	if retry := l.delivery.Failures.Retry; retry.Enabled() && !l.signal.IsSet() {
+		attempts = int(retry.MaxAttempts)
+	}
+
+	opts := db.DefaultActivityOptions()
+	opts.ScheduleToCloseTimeout = deadLetterRecordTimeout
+	opts.RetryPolicy.MaximumInterval = deadLetterRetryInterval
+	ctx = workflow.WithActivityOptions(ctx, opts)
+
+	dbErr := workflow.ExecuteActivity(ctx, db.CreateDeadLetterActivityName, db.CreateDeadLetterActivityParams{
+		DeadLetter: api.DeadLetter{
+			DeliveryID:  l.deliveryID(ticks),
+			RequesterID: l.requesterID,
+			Exchange:    ticks[0].Exchange,
+			Pair:        ticks[0].Pair,
+			Kind:        ticksKind(ticks),
+			Callback:    l.callback,
+			Signal:      l.signal,
+			Batch:       l.delivery.Batch.Enabled(),
+			Ticks:       ticks,
+			Error:       err.Error(),
+			Attempts:    attempts,
+			FailedAt:    workflow.Now(ctx),
+		},
+	}).Get(ctx, nil)
+	if dbErr != nil {
+		workflow.GetLogger(ctx).Error("Failed to record dead letter, giving up",
+			"error", dbErr, "callback", l.callback.Name, "count", len(ticks))
+	}
+}
+
+// ticksKind returns the kind of the first tick that is not a gap, if any.
+func ticksKind(ticks []tick.Tick) tick.Kind {
+	for _, t := range ticks {
+		if !t.IsGap() {
+			return t.Kind
+		}
+	}
+	return ""
+}
+
+// deliveryID returns the ID of the delivery of the ticks to the listener, which
+// is the ID of the delivery workflow for callbacks. It is deterministic, so that
+// a delivery keeps the same ID when it is retried or redelivered.
+func (l *listener) deliveryID(ticks []tick.Tick) string {
+	switch {
+	case l.signal.IsSet() && l.delivery.Batch.Enabled():
+		return deliveryWorkflowID("SignalTicks", l.requesterID, ticks)
+	case l.signal.IsSet():
+		return deliveryWorkflowID("SignalTick", l.requesterID, ticks[:1])
+	case l.delivery.Batch.Enabled():
+		return deliveryWorkflowID("SendTicks", l.requesterID, ticks)
+	default:
+		return deliveryWorkflowID("SendTick", l.requesterID, ticks[:1])
+	}
+}
+
+// deliver sends the ticks to the listener, either as a signal to its workflow
+// or by starting its callback workflow.
+func (l *listener) deliver(ctx workflow.Context, ticks []tick.Tick, dropped uint64) error {
+	id := l.deliveryID(ticks)
+	switch {
+	case l.signal.IsSet() && l.delivery.Batch.Enabled():
+		return sendToSignal(ctx, l.signal, api.ListenToTicksBatchCallbackWorkflowParams{
+			RequesterID: l.requesterID,
+			Ticks:       ticks,
+			Dropped:     dropped,
+		})
+	case l.signal.IsSet():
+		return sendToSignal(ctx, l.signal, api.ListenToTicksCallbackWorkflowParams{
+			RequesterID: l.requesterID,
+			Tick:        ticks[0],
+			Dropped:     dropped,
+		})
+	case l.delivery.Batch.Enabled():
+		return sendTicksBatchToCallback(ctx, id, ticks, dropped, l.callback, l.delivery.Failures.Retry, l.requesterID)
+	default:
+		return sendTickToCallback(ctx, id, ticks[0], dropped, l.callback, l.delivery.Failures.Retry, l.requesterID)
+	}
+}
+
+func createChildWorkflowOptions(
+	callback runtime.CallbackWorkflow,
+	retry api.RetryPolicy,
+) workflow.ChildWorkflowOptions {
+	opts := workflow.ChildWorkflowOptions{
+		TaskQueue:                callback.TaskQueueName,            // Execute in the client queue
+		ParentClosePolicy:        enums.PARENT_CLOSE_POLICY_ABANDON, // Do not close if the parent workflow closes
+		WorkflowExecutionTimeout: time.Second * 30,                  // Timeout if the child workflow does not complete
+		// Only deliver again the ticks whose delivery has failed
+		WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+	}
+
+	// Check if the timeout is set
+	if callback.ExecutionTimeout > 0 {
+		opts.WorkflowExecutionTimeout = callback.ExecutionTimeout
+	}
+
+	// Retry the failed deliveries if requested
+	if retry.Enabled() {
+		opts.RetryPolicy = &temporal.RetryPolicy{
+			InitialInterval:    retry.InitialInterval,
+			BackoffCoefficient: retry.BackoffCoefficient,
+			MaximumInterval:    retry.MaxInterval,
+			MaximumAttempts:    retry.MaxAttempts,
+		}
+	}
+
+	return opts
+}
+
+func sendTickToCallback(
+	ctx workflow.Context,
+	workflowID string,
+	t tick.Tick,
+	dropped uint64,
+	callback runtime.CallbackWorkflow,
+	retry api.RetryPolicy,
+	requesterID uuid.UUID,
+) error {
+	// Create child workflow options
+	opts := createChildWorkflowOptions(callback, retry)
+
+	opts.WorkflowID = workflowID
+	ctx = workflow.WithChildOptions(ctx, opts)
+
+	// Start a new child workflow
+	return ignoreAlreadyDelivered(ctx, workflowID, workflow.ExecuteChildWorkflow(ctx, callback.Name,
+		api.ListenToTicksCallbackWorkflowParams{
+			RequesterID: requesterID,
+			Tick:        t,
+			Dropped:     dropped,
+		}).Get(ctx, nil))
+}
+
+func sendTicksBatchToCallback(
+	ctx workflow.Context,
+	workflowID string,
+	ticks []tick.Tick,
+	dropped uint64,
+	callback runtime.CallbackWorkflow,
+	retry api.RetryPolicy,
+	requesterID uuid.UUID,
+) error {
+	// Create child workflow options
+	opts := createChildWorkflowOptions(callback, retry)
+
+	opts.WorkflowID = workflowID
+	ctx = workflow.WithChildOptions(ctx, opts)
+
+	// Start a new child workflow
+	return ignoreAlreadyDelivered(ctx, workflowID, workflow.ExecuteChildWorkflow(ctx, callback.Name,
+		api.ListenToTicksBatchCallbackWorkflowParams{
+			RequesterID: requesterID,
+			Ticks:       ticks,
+			Dropped:     dropped,
+		}).Get(ctx, nil))
+}
+
+// deliveryWorkflowID returns the ID of the workflow delivering ticks to a
+// requester. It is unique per requester and deterministic for the same ticks,
+// so that a delivery that is retried does not start a duplicate workflow.
+// The ticks content is hashed as several ticks can share the same time.
+func deliveryWorkflowID(prefix string, requesterID uuid.UUID, ticks []tick.Tick) string {
+	hash := fnv.New64a()
+	for _, t := range ticks {
+		b, _ := json.Marshal(t)
+		_, _ = hash.Write(b)
+	}
+
+	first := ticks[0]
+	return fmt.Sprintf(
+		"%s%s%s-%s-%s-%016x",
+		prefix,
+		strcase.ToCamel(first.Exchange),
+		strings.ReplaceAll(first.Pair, "-", ""),
+		requesterID.String(),
+		first.Time.Format(time.RFC3339Nano),
+		hash.Sum64(),
+	)
+}
+
+// ignoreAlreadyDelivered returns nil if the error is due to the delivery
+// workflow having already been executed successfully. If it is still being
+// executed, the delivery is not confirmed and ErrDeliveryInProgress is returned.
+func ignoreAlreadyDelivered(ctx workflow.Context, workflowID string, err error) error {
+	if err == nil || !temporal.IsWorkflowExecutionAlreadyStartedError(err) {
+		return err
+	}
+
+	// Check that the existing delivery has completed
+	res, statusErr := activities.ExecuteGetWorkflowStatus(ctx, activities.GetWorkflowStatusActivityParams{
+		WorkflowID: workflowID,
+	})
+	if statusErr != nil {
+		return fmt.Errorf("checking the existing delivery %q: %w", workflowID, statusErr)
+	} else if res.Status != enums.WORKFLOW_EXECUTION_STATUS_COMPLETED {
+		return fmt.Errorf("%w: %q is %s", ErrDeliveryInProgress, workflowID, res.Status)
+	}
+
+	workflow.GetLogger(ctx).Debug("Ticks already delivered, skipping", "workflow_id", workflowID)
+	return nil
+}
+
+func sendToSignal(ctx workflow.Context, target api.SignalTarget, payload any) error {
+	return workflow.SignalExternalWorkflow(ctx, target.WorkflowID, "", target.SignalName, payload).Get(ctx, nil)
+}
+
+// notifySubscriptionEnded starts the callback workflow notifying the end of a
+// subscription, without waiting for its completion.
+func notifySubscriptionEnded(
+	ctx workflow.Context,
+	callback runtime.CallbackWorkflow,
+	params api.SubscriptionEndedCallbackWorkflowParams,
+) error {
+	// Create child workflow options
+	opts := createChildWorkflowOptions(callback, api.RetryPolicy{})
+
+	// Generate a unique ID for the workflow
+	opts.WorkflowID = fmt.Sprintf(
+		"SubscriptionEnded%s%s-%s-%s",
+		strcase.ToCamel(params.Exchange),
+		strings.ReplaceAll(params.Pair, "-", ""),
+		params.RequesterID.String(),
+		workflow.Now(ctx).Format(time.RFC3339Nano),
+	)
+	ctx = workflow.WithChildOptions(ctx, opts)
+
+	// Start a new child workflow
+	return workflow.ExecuteChildWorkflow(ctx, callback.Name, params).GetChildWorkflowExecution().Get(ctx, nil)
+}
@@ -0,0 +1,161 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestListenerSuite(t *testing.T) {
+	suite.Run(t, new(ListenerSuite))
+}
+
+type ListenerSuite struct {
+	suite.Suite
+}
+
+func (suite *ListenerSuite) pushTicks(policy api.OverflowPolicy, count int) *listener {
+	return suite.pushTicksWithOptions(api.DeliveryOptions{
+		BufferSize:     2,
+		OverflowPolicy: policy,
+	}, count)
+}
+
+func (suite *ListenerSuite) pushTicksWithOptions(delivery api.DeliveryOptions, count int) *listener {
+	l := newListener(uuid.New(), runtime.CallbackWorkflow{Name: "Callback"}, api.SignalTarget{}, delivery, time.Now())
+
+	for i := 0; i < count; i++ {
+		l.Push(tick.FromBook("exchange", "ETH-USDC",
+			time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC), float64(i), 1, float64(i+1), 1))
+	}
+
+	return l
+}
+
+func (suite *ListenerSuite) queuedBids(l *listener) []float64 {
+	bids := make([]float64, 0, len(l.queue))
+	for _, t := range l.queue {
+		bids = append(bids, t.Bid)
+	}
+	return bids
+}
+
+func (suite *ListenerSuite) TestPushWithRoom() {
+	l := suite.pushTicks(api.OverflowPolicyDropNewest, 2)
+	suite.Require().Equal([]float64{0, 1}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(0), l.dropped)
+}
+
+func (suite *ListenerSuite) TestPushDropOldest() {
+	l := suite.pushTicks("", 4)
+	suite.Require().Equal([]float64{2, 3}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(2), l.dropped)
+	suite.Require().Equal(uint64(2), l.totalDropped)
+}
+
+func (suite *ListenerSuite) TestPushDropNewest() {
+	l := suite.pushTicks(api.OverflowPolicyDropNewest, 4)
+	suite.Require().Equal([]float64{0, 1}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(2), l.dropped)
+}
+
+func (suite *ListenerSuite) TestPushConflate() {
+	l := suite.pushTicks(api.OverflowPolicyConflate, 4)
+	suite.Require().Equal([]float64{0, 3}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(2), l.dropped)
+}
+
+func (suite *ListenerSuite) TestPushWithConflation() {
+	l := suite.pushTicksWithOptions(api.DeliveryOptions{Conflate: true}, 4)
+	suite.Require().Equal([]float64{3}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(3), l.dropped)
+}
+
+func (suite *ListenerSuite) TestPushBlock() {
+	// The ticks are kept over the buffer size without waiting for room
+	l := suite.pushTicks(api.OverflowPolicyBlock, 4)
+	suite.Require().Equal([]float64{0, 1, 2, 3}, suite.queuedBids(l))
+	suite.Require().Equal(uint64(0), l.dropped)
+}
+
+func (suite *ListenerSuite) TestPushStopped() {
+	l := suite.pushTicks(api.OverflowPolicyBlock, 0)
+	l.Stop()
+	l.Push(tick.Tick{})
+	suite.Require().Len(l.queue, 0)
+}
+
+func (suite *ListenerSuite) pushPrices(delivery api.DeliveryOptions, prices ...float64) *listener {
+	l := newListener(uuid.New(), runtime.CallbackWorkflow{Name: "Callback"}, api.SignalTarget{}, delivery, time.Now())
+	for i, p := range prices {
+		t := time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC)
+		if p < 0 {
+			l.Push(tick.FromGap("exchange", "ETH-USDC", t, t))
+		} else {
+			l.Push(tick.FromBook("exchange", "ETH-USDC", t, p, 1, p, 1))
+		}
+	}
+	return l
+}
+
+func (suite *ListenerSuite) TestPushWithMinPriceChange() {
+	l := suite.pushPrices(api.DeliveryOptions{MinPriceChange: 1}, 100, 100.5, 101, 101.1, 99.9, 99)
+	suite.Require().Equal([]float64{100, 101.1, 99.9}, suite.queuedBids(l))
+}
+
+func (suite *ListenerSuite) TestPushWithMinPriceChangeBps() {
+	l := suite.pushPrices(api.DeliveryOptions{MinPriceChangeBps: 10}, 100, 100.1, 100.11, 100.2, 100.22)
+	suite.Require().Equal([]float64{100, 100.11, 100.22}, suite.queuedBids(l))
+}
+
+func (suite *ListenerSuite) TestPushWithMinPriceChangeAndFullBuffer() {
+	// The dropped tick does not become the reference of the price change
+	l := suite.pushPrices(api.DeliveryOptions{
+		MinPriceChange: 1,
+		BufferSize:     1,
+		OverflowPolicy: api.OverflowPolicyDropNewest,
+	}, 100, 102)
+	suite.Require().Equal([]float64{100}, suite.queuedBids(l))
+
+	l.queue = nil
+	l.Push(tick.FromBook("exchange", "ETH-USDC", time.Now(), 101.5, 1, 101.5, 1))
+	suite.Require().Equal([]float64{101.5}, suite.queuedBids(l))
+}
+
+func (suite *ListenerSuite) TestPushGapWithoutOptIn() {
+	// Gap ticks are skipped but the following tick is kept
+	l := suite.pushPrices(api.DeliveryOptions{MinPriceChange: 1}, 100, 100.5, -1, 100.6, 100.7)
+	suite.Require().Equal([]float64{100, 100.6}, suite.queuedBids(l))
+}
+
+func (suite *ListenerSuite) TestPushWithMinPriceChangeAndGap() {
+	// Gap ticks are kept and the following tick is kept too
+	l := suite.pushPrices(api.DeliveryOptions{MinPriceChange: 1, DeliverGaps: true}, 100, 100.5, -1, 100.6, 100.7)
+	suite.Require().Len(l.queue, 3)
+	suite.Require().Equal(100.0, l.queue[0].Bid)
+	suite.Require().True(l.queue[1].IsGap())
+	suite.Require().Equal(100.6, l.queue[2].Bid)
+}
+
+func (suite *ListenerSuite) TestDeliveryWorkflowID() {
+	first, second := uuid.New(), uuid.New()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t := tick.FromBook("exchange", "ETH-USDC", at, 1, 1, 2, 1)
+	same := tick.FromBook("exchange", "ETH-USDC", at, 1, 1, 2, 1)
+	other := tick.FromBook("exchange", "ETH-USDC", at, 1, 2, 2, 1)
+
+	id := deliveryWorkflowID("SendTick", first, []tick.Tick{t})
+	suite.Require().Contains(id, first.String())
+	suite.Require().Equal(id, deliveryWorkflowID("SendTick", first, []tick.Tick{same}))
+	suite.Require().NotEqual(id, deliveryWorkflowID("SendTick", second, []tick.Tick{t}))
+	suite.Require().NotEqual(id, deliveryWorkflowID("SendTick", first, []tick.Tick{other}))
+	suite.Require().NotEqual(id, deliveryWorkflowID("SendTick", first, []tick.Tick{t, other}))
+}
@@ -0,0 +1,163 @@
+package svc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// recorderMaxBatchSize is the maximum number of ticks persisted at once.
+	recorderMaxBatchSize = 100
+	// recorderMaxLatency is the maximum time a tick waits before being persisted.
+	recorderMaxLatency = time.Second
+	// recorderRetryDelay is the time waited before persisting again ticks that
+	// failed to be persisted.
+	recorderRetryDelay = 10 * time.Second
+	// recorderMaxPending is the maximum number of ticks waiting to be persisted
+	// when the database is unavailable, after which the oldest ones are dropped.
+	recorderMaxPending = 5000
+)
+
+// ErrTicksNotRecorded is the error returned when received ticks cannot be persisted.
+var ErrTicksNotRecorded = errors.New("ticks not recorded")
+
+// ticksRecorder persists the ticks received by a sentry in batches, from a
+// workflow routine to avoid slowing down the ticks delivery. Ticks that failed
+// to be persisted are kept and persisted again later, up to a limit after which
+// the oldest ones are replaced by a gap.
+type ticksRecorder struct {
+	db      db.DB
+	feed    tick.Kind
+	pending []tick.Tick
+	closed  bool
+	done    bool
+}
+
+// startTicksRecorder creates a recorder for the ticks of the feed with the
+// ticks not persisted by a previous run and starts its persisting routine.
+func (wf *workflows) startTicksRecorder(
+	ctx workflow.Context,
+	feed tick.Kind,
+	unrecorded []tick.Tick,
+) *ticksRecorder {
+	r := &ticksRecorder{
+		db:      wf.db,
+		feed:    feed,
+		pending: unrecorded,
+	}
+	workflow.Go(ctx, r.run)
+	return r
+}
+
+// Add adds a tick to the next batch to persist.
+func (r *ticksRecorder) Add(t tick.Tick) {
+	r.pending = append(r.pending, t)
+}
+
+// Close persists the remaining ticks and waits for the persisting routine to be
+// done. It returns the ticks that could not be persisted.
+func (r *ticksRecorder) Close(ctx workflow.Context) []tick.Tick {
+	r.closed = true
+	_ = workflow.Await(ctx, func() bool {
+		return r.done
+	})
+	return r.pending
+}
+
+func (r *ticksRecorder) run(ctx workflow.Context) {
+	defer func() { r.done = true }()
+
+	for {
+		// Wait for a first tick to persist
+		err := workflow.Await(ctx, func() bool {
+			return len(r.pending) > 0 || r.closed
+		})
+		if err != nil || (r.closed && len(r.pending) == 0) {
+			return
+		}
+
+		// Wait for the batch to be full or the maximum latency to be reached
+		_, err = workflow.AwaitWithTimeout(ctx, recorderMaxLatency, func() bool {
+			return len(r.pending) >= recorderMaxBatchSize || r.closed
+		})
+		if err != nil {
+			return
+		}
+
+		if r.flush(ctx) {
+			continue
+		}
+
+		// Stop trying when closing, the remaining ticks are returned to the caller
+		if r.closed {
+			return
+		}
+
+		// Drop the oldest ticks when too many are waiting, as they cannot be
+		// kept forever, so that the sentry keeps delivering the new ones
+		var dropped int
+		if r.pending, dropped = dropOldestTicks(r.pending, recorderMaxPending); dropped > 0 {
+			workflow.GetLogger(ctx).Warn("Too many ticks waiting to be persisted, dropping the oldest ones",
+				"dropped", dropped,
+				"pending", len(r.pending))
+		}
+
+		// Wait before trying again
+		if _, err := workflow.AwaitWithTimeout(ctx, recorderRetryDelay, func() bool {
+			return r.closed
+		}); err != nil {
+			return
+		}
+	}
+}
+
+// flush persists the next batch of pending ticks and returns true if it
+// succeeded. Otherwise the batch is kept to be persisted again.
+func (r *ticksRecorder) flush(ctx workflow.Context) bool {
+	logger := workflow.GetLogger(ctx)
+
+	// Take the pending ticks
+	size := min(len(r.pending), recorderMaxBatchSize)
+	batch := r.pending[:size]
+
+	// Persist them
+	ctx = workflow.WithActivityOptions(ctx, db.DefaultActivityOptions())
+	err := workflow.ExecuteActivity(ctx, r.db.CreateTicksActivity, db.CreateTicksActivityParams{
+		Ticks: batch,
+		Feed:  r.feed,
+	}).Get(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to persist ticks, keeping them to try again",
+			"error", err,
+			"count", len(batch),
+			"pending", len(r.pending))
+		return false
+	}
+
+	r.pending = r.pending[size:]
+	return true
+}
+
+// dropOldestTicks replaces the oldest ticks by a gap tick if there are more than
+// the maximum, so that the hole is persisted. It returns the remaining ticks and
+// the count of dropped ones.
+func dropOldestTicks(ticks []tick.Tick, maxTicks int) ([]tick.Tick, int) {
+	if len(ticks) <= maxTicks {
+		return ticks, 0
+	}
+
+	// Drop the oldest ticks, keeping room for the gap
+	count := len(ticks) - maxTicks + 1
+	first, last := ticks[0], ticks[count-1]
+	start := first.Time
+	if first.IsGap() {
+		start = first.GapStart
+	}
+
+	gap := tick.FromGap(first.Exchange, first.Pair, start, last.Time)
+	return append([]tick.Tick{gap}, ticks[count:]...), count
+}
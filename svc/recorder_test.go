@@ -0,0 +1,54 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRecorderSuite(t *testing.T) {
+	suite.Run(t, new(RecorderSuite))
+}
+
+type RecorderSuite struct {
+	suite.Suite
+}
+
+func (suite *RecorderSuite) tickAt(seconds int) tick.Tick {
+	return tick.FromBook("exchange", "ETH-USDC",
+		time.Date(2024, 1, 1, 0, 0, seconds, 0, time.UTC),
+		float64(seconds), 1, float64(seconds+1), 1)
+}
+
+func (suite *RecorderSuite) TestDropOldestTicks() {
+	ticks := []tick.Tick{suite.tickAt(0), suite.tickAt(1), suite.tickAt(2), suite.tickAt(3)}
+
+	// Nothing is dropped under the maximum
+	kept, dropped := dropOldestTicks(ticks, 4)
+	suite.Require().Equal(0, dropped)
+	suite.Require().Equal(ticks, kept)
+
+	// The oldest ticks are replaced by a gap
+	kept, dropped = dropOldestTicks(ticks, 3)
+	suite.Require().Equal(2, dropped)
+	suite.Require().Len(kept, 3)
+	suite.Require().True(kept[0].IsGap())
+	suite.Require().Equal(suite.tickAt(0).Time, kept[0].GapStart)
+	suite.Require().Equal(suite.tickAt(1).Time, kept[0].Time)
+	suite.Require().Equal(suite.tickAt(2).Time, kept[1].Time)
+	suite.Require().Equal(suite.tickAt(3).Time, kept[2].Time)
+
+	// A previous gap is extended
+	kept, dropped = dropOldestTicks(append(kept, suite.tickAt(4)), 3)
+	suite.Require().Equal(2, dropped)
+	suite.Require().Len(kept, 3)
+	suite.Require().True(kept[0].IsGap())
+	suite.Require().Equal(suite.tickAt(0).Time, kept[0].GapStart)
+	suite.Require().Equal(suite.tickAt(2).Time, kept[0].Time)
+	suite.Require().Equal(suite.tickAt(4).Time, kept[2].Time)
+}
@@ -26,6 +26,20 @@ func (wf *workflows) RegisterForTicksListeningWorkflow(
 	if params.Pair == "" {
 		return api.RegisterForTicksListeningWorkflowResults{}, errors.New("pair must be provided")
 	}
+	if err := params.Kind.Validate(); err != nil {
+		return api.RegisterForTicksListeningWorkflowResults{}, err
+	}
+	if (params.Callback.Name == "") == !params.Signal.IsSet() {
+		return api.RegisterForTicksListeningWorkflowResults{},
+			errors.New("exactly one of callback or signal must be provided")
+	}
+	if err := params.Delivery.Validate(); err != nil {
+		return api.RegisterForTicksListeningWorkflowResults{}, err
+	}
+	if params.Signal.IsSet() && params.Delivery.Failures.Retry.Enabled() {
+		return api.RegisterForTicksListeningWorkflowResults{},
+			fmt.Errorf("%w: signals cannot be retried", api.ErrInvalidDeliveryOptions)
+	}
 
 	// Check if exchange+pair exists
 	if err := wf.checkPairAndExchange(ctx, params.Pair, params.Exchange); err != nil {
@@ -38,12 +52,15 @@ func (wf *workflows) RegisterForTicksListeningWorkflow(
 		SignalParams: signals.RegisterToTicksListeningSignalParams{
 			RequesterID:      params.RequesterID,
 			CallbackWorkflow: params.Callback,
+			Signal:           params.Signal.WithDefaults(),
+			Delivery:         params.Delivery,
 		},
-		WorkflowID:   sentryWorkflowName(params.Exchange, params.Pair),
+		WorkflowID:   sentryWorkflowName(params.Exchange, params.Pair, params.Kind),
 		WorkflowName: ticksSentryWorkflowName,
 		WorkflowParams: ticksSentryWorkflowParams{
 			Exchange: params.Exchange,
 			Symbol:   params.Pair,
+			Kind:     params.Kind.OrDefault(),
 		},
 		TaskQueue: api.WorkerTaskQueueName,
 	}); err != nil {
@@ -54,6 +71,11 @@ func (wf *workflows) RegisterForTicksListeningWorkflow(
 }
 
 func (wf *workflows) checkPairAndExchange(ctx workflow.Context, pair string, exchange string) error {
+	// Check pair on local exchanges (i.e. offline exchanges) first
+	if pairs, ok := wf.localPairs(ctx, exchange); ok {
+		return checkPairInList(pair, exchange, pairs)
+	}
+
 	// Get exchange info
 	result, err := wf.exchangesSvc.GetExchange(ctx, exchangesapi.GetExchangeWorkflowParams{
 		Name: exchange,
@@ -65,8 +87,32 @@ func (wf *workflows) checkPairAndExchange(ctx workflow.Context, pair string, exc
 		return err
 	}
 
+	return checkPairInList(pair, exchange, result.Exchange.Pairs)
+}
+
+// localPairs returns the pairs of the exchange if they are defined locally.
+// It is executed as a side effect as it depends on the worker configuration.
+func (wf *workflows) localPairs(ctx workflow.Context, exchange string) ([]string, bool) {
+	type localPairsResult struct {
+		Pairs []string
+		OK    bool
+	}
+
+	var res localPairsResult
+	encoded := workflow.SideEffect(ctx, func(_ workflow.Context) any {
+		pairs, ok := wf.exchangesAdapter.LocalPairs(exchange)
+		return localPairsResult{Pairs: pairs, OK: ok}
+	})
+	if err := encoded.Get(&res); err != nil {
+		return nil, false
+	}
+
+	return res.Pairs, res.OK
+}
+
+func checkPairInList(pair, exchange string, pairs []string) error {
 	// Check if pair exists
-	for _, p := range result.Exchange.Pairs {
+	for _, p := range pairs {
 		if p == pair {
 			return nil
 		}
@@ -0,0 +1,48 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+
+	"github.com/cryptellation/ticks/api"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestRegisterSuite(t *testing.T) {
+	suite.Run(t, new(RegisterSuite))
+}
+
+type RegisterSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (suite *RegisterSuite) SetupTest() {
+	wf := &workflows{}
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	suite.env.RegisterWorkflowWithOptions(wf.RegisterForTicksListeningWorkflow, workflow.RegisterOptions{
+		Name: api.RegisterForTicksListeningWorkflowName,
+	})
+}
+
+func (suite *RegisterSuite) TestSignalWithRetry() {
+	suite.env.ExecuteWorkflow(api.RegisterForTicksListeningWorkflowName, api.RegisterForTicksListeningWorkflowParams{
+		RequesterID: uuid.New(),
+		Exchange:    "exchange",
+		Pair:        "ETH-USDC",
+		Signal:      api.SignalTarget{WorkflowID: "Consumer"},
+		Delivery: api.DeliveryOptions{Failures: api.FailurePolicy{
+			Retry: api.RetryPolicy{MaxAttempts: 3},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().ErrorContains(suite.env.GetWorkflowError(), "signals cannot be retried")
+}
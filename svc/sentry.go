@@ -1,20 +1,15 @@
 package svc
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/ticks/api"
 	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/cryptellation/ticks/svc/exchanges"
 	"github.com/cryptellation/ticks/svc/internal/signals"
-	"github.com/google/uuid"
 	"github.com/iancoleman/strcase"
-	"go.temporal.io/api/enums/v1"
-	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -28,6 +23,13 @@ type (
 	ticksSentryWorkflowParams struct {
 		Exchange string
 		Symbol   string
+		Kind     tick.Kind
+
+		// State carried over when the sentry continues as new.
+		Listeners       []sentryListenerState
+		PendingTicks    []tick.Tick
+		LastTick        *tick.Tick
+		UnrecordedTicks []tick.Tick
 	}
 
 	// ticksSentryWorkflowResults is the output results for the TicksSentryWorkflow.
@@ -41,23 +43,88 @@ func (wf *workflows) ticksSentryWorkflow(
 	logger := workflow.GetLogger(ctx)
 	logger.Info("Listening to ticks",
 		"exchange", params.Exchange,
-		"symbol", params.Symbol)
+		"symbol", params.Symbol,
+		"kind", params.Kind,
+		"carried_listeners", len(params.Listeners),
+		"carried_ticks", len(params.PendingTicks))
 
 	// Get signal channels
 	registerSignalChannel := workflow.GetSignalChannel(ctx, signals.RegisterToTicksListeningSignalName)
 	unregisterSignalChannel := workflow.GetSignalChannel(ctx, signals.UnregisterFromTicksListeningSignalName)
 	newTickReceivedSignalChannel := workflow.GetSignalChannel(ctx, signals.NewTickReceivedSignalName)
 
-	// Start listening to ticks
-	cancelListening := wf.sentryStartListeningActivity(ctx, params)
+	// Expose the last received tick
+	lastTick := params.LastTick
+	if err := workflow.SetQueryHandler(ctx, signals.LastTickQueryName, func() (*tick.Tick, error) {
+		return lastTick, nil
+	}); err != nil {
+		return ticksSentryWorkflowResults{}, err
+	}
 
-	// Create listeners
-	listeners := make(map[string]workflow.Channel)
+	// Get the history size after which the sentry continues as new
+	maxHistoryEvents := wf.sentryMaxHistoryEvents(ctx)
+
+	// Start listening to ticks
+	_, cancelListening := wf.sentryStartListeningActivity(ctx, params)
+
+	// Start recording ticks into database
+	recorder := wf.startTicksRecorder(ctx, params.Kind.OrDefault(), params.UnrecordedTicks)
+
+	// Create listeners, restore the ones from the previous run and expose them
+	listeners := make(map[string]*listener)
+	restoreListeners(ctx, listeners, params.Listeners)
+	if err := workflow.SetQueryHandler(ctx, signals.ListenersQueryName, func() (api.SentryInfo, error) {
+		return sentryInfo(params, listeners), nil
+	}); err != nil {
+		return ticksSentryWorkflowResults{}, err
+	}
 	handleListenTicksSignals(ctx, listeners, registerSignalChannel, unregisterSignalChannel)
 
+	// Process a tick: record it and send it to all listeners
+	processTick := func(t tick.Tick) {
+		recorder.Add(t)
+		if !t.IsGap() {
+			last := t
+			lastTick = &last
+		}
+
+		// Send event to all listeners
+		logger.Debug("Sending tick to listeners",
+			"tick", t,
+			"listeners_count", len(listeners))
+		keys := workflow.DeterministicKeys(listeners)
+		for _, k := range keys {
+			listeners[k].Push(t)
+		}
+	}
+
+	// Stop the current run and carry its state over to a new one
+	continueAsNew := func() error {
+		return sentryContinueAsNew(ctx, sentryContinueAsNewParams{
+			Params:                       params,
+			Listeners:                    listeners,
+			LastTick:                     lastTick,
+			CancelListening:              cancelListening,
+			Recorder:                     recorder,
+			RegisterSignalChannel:        registerSignalChannel,
+			UnregisterSignalChannel:      unregisterSignalChannel,
+			NewTickReceivedSignalChannel: newTickReceivedSignalChannel,
+		})
+	}
+
+	// Process ticks received by the previous run
+	for _, t := range params.PendingTicks {
+		processTick(t)
+	}
+
 	// Loop over ticks
 	var t tick.Tick
 	for len(listeners) > 0 {
+		// Continue as new if the history is too long
+		if shouldContinueAsNew(ctx, maxHistoryEvents) {
+			return ticksSentryWorkflowResults{}, continueAsNew()
+		}
+
 		// Get next tick
 		logger.Debug("Listening to next tick",
 			"listeners_count", listeners)
@@ -67,28 +134,42 @@ func (wf *workflows) ticksSentryWorkflow(
 		// TODO(#4): make new ticks signal handling more asynchronous
 		handleListenTicksSignals(ctx, listeners, registerSignalChannel, unregisterSignalChannel)
 
-		// Send event to all listeners
-		logger.Debug("Sending tick to listeners",
-			"tick", t,
-			"listeners_count", len(listeners))
-		keys := workflow.DeterministicKeys(listeners)
-		for _, k := range keys {
-			_ = listeners[k].SendAsync(t)
-		}
+		processTick(t)
 	}
 
-	// Cancel listening and cleanup signals
+	// Cancel listening
 	logger.Debug("No more listeners, cancel listening")
 	cancelListening()
 
-	// Cleanup remaining signals
-	// TODO(#5): clean up new ticks signals when quitting workflow
-	// TODO(#6): clean up unregister signals when quitting workflow
-	// TODO(#7): clean up register signals and trigger a new workflow if needed when quitting workflow
+	// Persist remaining ticks
+	unrecorded := recorder.Close(ctx)
+
+	// Drain the register and unregister signals received in the meantime, and
+	// continue as new if some listeners registered before the sentry exits
+	handleListenTicksSignals(ctx, listeners, registerSignalChannel, unregisterSignalChannel)
+	if len(listeners) > 0 {
+		logger.Info("Listeners registered while stopping, continuing as new",
+			"listeners_count", len(listeners))
+		return ticksSentryWorkflowResults{}, continueAsNew()
+	}
+
+	// Drain the remaining ticks as there is no one to send them to
+	dropped := 0
+	for newTickReceivedSignalChannel.ReceiveAsync(&t) {
+		dropped++
+	}
 
 	logger.Info("Stop listening to ticks",
 		"exchange", params.Exchange,
-		"symbol", params.Symbol)
+		"symbol", params.Symbol,
+		"kind", params.Kind,
+		"dropped_ticks", dropped)
+
+	// Fail if some received ticks could not be recorded
+	if len(unrecorded) > 0 {
+		return ticksSentryWorkflowResults{}, fmt.Errorf("%w: %d ticks could not be persisted",
+			ErrTicksNotRecorded, len(unrecorded))
+	}
 
 	return ticksSentryWorkflowResults{}, nil
 }
@@ -96,7 +177,7 @@ func (wf *workflows) ticksSentryWorkflow(
 func (wf *workflows) sentryStartListeningActivity(
 	ctx workflow.Context,
 	params ticksSentryWorkflowParams,
-) func() {
+) (workflow.Future, workflow.CancelFunc) {
 	// Set activity options
 	activityOptions := exchanges.DefaultActivityOptions()
 	activityOptions.ScheduleToCloseTimeout = 365 * 24 * time.Hour
@@ -106,19 +187,35 @@ func (wf *workflows) sentryStartListeningActivity(
 
 	// Execute activity with cancel
 	ctx, cancelActivity := workflow.WithCancel(ctx)
-	_ = workflow.ExecuteActivity(
+	future := workflow.ExecuteActivity(
 		ctx, wf.exchangesAdapter.ListenSymbolActivity, exchanges.ListenSymbolParams{
 			ParentWorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
 			Exchange:         params.Exchange,
 			Symbol:           params.Symbol,
+			Kind:             params.Kind.OrDefault(),
 		})
 
-	return cancelActivity
+	return future, cancelActivity
+}
+
+func sentryInfo(params ticksSentryWorkflowParams, listeners map[string]*listener) api.SentryInfo {
+	info := api.SentryInfo{
+		Exchange:      params.Exchange,
+		Pair:          params.Symbol,
+		Kind:          params.Kind.OrDefault(),
+		Subscriptions: make([]api.Subscription, 0, len(listeners)),
+	}
+
+	for _, k := range workflow.DeterministicKeys(listeners) {
+		info.Subscriptions = append(info.Subscriptions, listeners[k].Info())
+	}
+
+	return info
 }
 
 func handleListenTicksSignals(
 	ctx workflow.Context,
-	listeners map[string]workflow.Channel,
+	listeners map[string]*listener,
 	registerSignalChannel, unregisterSignalChannel workflow.ReceiveChannel,
 ) {
 	logger := workflow.GetLogger(ctx)
@@ -131,7 +228,7 @@ func handleListenTicksSignals(
 
 func handleRegisterSignals(
 	ctx workflow.Context,
-	listeners map[string]workflow.Channel,
+	listeners map[string]*listener,
 	registerSignalChannel workflow.ReceiveChannel,
 ) {
 	logger := workflow.GetLogger(ctx)
@@ -144,21 +241,23 @@ func handleRegisterSignals(
 		if detected {
 			logger.Info("Received register signal", "params", registerParams)
 
-			// Create a new listener
-			listeners[registerParams.RequesterID.String()] = workflow.NewBufferedChannel(ctx, 0)
+			// Replace the previous listener with the same requester, if any
+			if previous, exists := listeners[registerParams.RequesterID.String()]; exists {
+				previous.Stop()
+			}
 
-			// Start a new routine to send ticks to the listener
-			workflow.Go(ctx, sendToTickListenerRoutine(
-				registerParams.CallbackWorkflow,
-				listeners,
-				registerParams.RequesterID))
+			// Create a new listener and start its routine to send it ticks
+			l := newListener(registerParams.RequesterID, registerParams.CallbackWorkflow,
+				registerParams.Signal, registerParams.Delivery, workflow.Now(ctx))
+			listeners[registerParams.RequesterID.String()] = l
+			workflow.Go(ctx, l.routine(listeners))
 		}
 	}
 }
 
 func handleUnregisterSignals(
 	ctx workflow.Context,
-	listeners map[string]workflow.Channel,
+	listeners map[string]*listener,
 	unregisterSignalChannel workflow.ReceiveChannel,
 ) {
 	logger := workflow.GetLogger(ctx)
@@ -172,103 +271,34 @@ func handleUnregisterSignals(
 			// Log the received unregister signal
 			logger.Info("Received unregister signal", "params", unregisterParams)
 
-			// Remove the listener
-			delete(listeners, unregisterParams.RequesterID.String())
+			// Stop and remove the listener
+			if l, exists := listeners[unregisterParams.RequesterID.String()]; exists {
+				l.Stop()
+				delete(listeners, unregisterParams.RequesterID.String())
+			}
 		}
 	}
 }
 
-func sendToTickListenerRoutine(
-	callback runtime.CallbackWorkflow,
-	listeners map[string]workflow.Channel,
-	requesterID uuid.UUID,
-) func(ctx workflow.Context) {
-	ch := listeners[requesterID.String()]
-
-	return func(ctx workflow.Context) {
-		processTicksForListener(ctx, ch, callback, requesterID, listeners)
-	}
-}
-
-func createChildWorkflowOptions(callback runtime.CallbackWorkflow) workflow.ChildWorkflowOptions {
-	opts := workflow.ChildWorkflowOptions{
-		TaskQueue:                callback.TaskQueueName,            // Execute in the client queue
-		ParentClosePolicy:        enums.PARENT_CLOSE_POLICY_ABANDON, // Do not close if the parent workflow closes
-		WorkflowExecutionTimeout: time.Second * 30,                  // Timeout if the child workflow does not complete
-	}
-
-	// Check if the timeout is set
-	if callback.ExecutionTimeout > 0 {
-		opts.WorkflowExecutionTimeout = callback.ExecutionTimeout
-	}
-
-	return opts
-}
-
-func processTicksForListener(
-	ctx workflow.Context,
-	ch workflow.Channel,
-	callback runtime.CallbackWorkflow,
-	requesterID uuid.UUID,
-	listeners map[string]workflow.Channel,
-) {
-	logger := workflow.GetLogger(ctx)
-
-	for {
-		// Receive next event
-		var t tick.Tick
-		ch.Receive(ctx, &t)
+func sentryWorkflowName(exchange, pair string, kind tick.Kind) string {
+	name := fmt.Sprintf("Sentry%s%s", strcase.ToCamel(exchange), strings.ReplaceAll(pair, "-", ""))
 
-		// Send tick to callback
-		if err := sendTickToCallback(ctx, t, callback, requesterID); err != nil {
-			if shouldStopProcessing(ctx, err, callback) {
-				break
-			}
-		}
+	// Keep the original name for book ticks to stay compatible with running sentries
+	if kind.OrDefault() != tick.KindBook {
+		name += strcase.ToCamel(string(kind))
 	}
 
-	// Remove listener as it has been in error or stopped.
-	logger.Debug("Removing listener", "callback", callback.Name)
-	delete(listeners, requesterID.String())
-}
-
-func sendTickToCallback(
-	ctx workflow.Context,
-	t tick.Tick,
-	callback runtime.CallbackWorkflow,
-	requesterID uuid.UUID,
-) error {
-	// Create child workflow options
-	opts := createChildWorkflowOptions(callback)
-
-	// Generate a unique ID for the workflow
-	opts.WorkflowID = fmt.Sprintf(
-		"SendTick%s%s-%s",
-		strcase.ToCamel(t.Exchange),
-		strings.ReplaceAll(t.Pair, "-", ""),
-		t.Time.Format(time.RFC3339Nano),
-	)
-	ctx = workflow.WithChildOptions(ctx, opts)
-
-	// Start a new child workflow
-	return workflow.ExecuteChildWorkflow(ctx, callback.Name, api.ListenToTicksCallbackWorkflowParams{
-		RequesterID: requesterID,
-		Tick:        t,
-	}).Get(ctx, nil)
+	return name
 }
 
-func shouldStopProcessing(ctx workflow.Context, err error, callback runtime.CallbackWorkflow) bool {
-	logger := workflow.GetLogger(ctx)
-	var timeoutErr *temporal.TimeoutError
-	if errors.As(err, &timeoutErr) {
-		logger.Debug("Listener has timed out, exiting", "callback", callback.Name)
-		return true
+// sentryWorkflowIDPrefix returns the prefix of the workflow IDs of the
+// sentries that can match the exchange and pair. The pair is only part of
+// the prefix when the exchange is set.
+func sentryWorkflowIDPrefix(exchange, pair string) string {
+	if exchange == "" {
+		return "Sentry"
 	}
 
-	logger.Error("Listener has errored, continuing", "error", err, "callback", callback.Name)
-	return false
-}
-
-func sentryWorkflowName(exchange, pair string) string {
-	return fmt.Sprintf("Sentry%s%s", strcase.ToCamel(exchange), strings.ReplaceAll(pair, "-", ""))
+	// The book ticks sentry name is the base of the other kinds names
+	return sentryWorkflowName(exchange, pair, tick.KindBook)
 }
@@ -0,0 +1,166 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// sentrySendingTimeout is the maximum time the sentry waits for the listeners
+// to finish sending their ticks before continuing as new.
+const sentrySendingTimeout = 10 * time.Second
+
+// sentryListenerState is the state of a listener carried over when the
+// sentry continues as new.
+type sentryListenerState struct {
+	RequesterID  uuid.UUID
+	Callback     runtime.CallbackWorkflow
+	Signal       api.SignalTarget
+	Delivery     api.DeliveryOptions
+	RegisteredAt time.Time
+	Queue        []tick.Tick
+	Sampled      *tick.Tick
+	Reference    *tick.Tick
+	Dropped      uint64
+	Delivered    uint64
+	Failed       uint64
+	TotalDropped uint64
+
+	ConsecutiveFailures uint
+	PausedUntil         time.Time
+}
+
+// sentryMaxHistoryEvents returns the history size after which the sentry
+// continues as new. It is executed as a side effect as it depends on the
+// worker configuration.
+func (wf *workflows) sentryMaxHistoryEvents(ctx workflow.Context) int {
+	var maxEvents int
+	encoded := workflow.SideEffect(ctx, func(_ workflow.Context) any {
+		return wf.options.SentryMaxHistoryEvents
+	})
+	if err := encoded.Get(&maxEvents); err != nil || maxEvents <= 0 {
+		return DefaultSentryMaxHistoryEvents
+	}
+
+	return maxEvents
+}
+
+// shouldContinueAsNew returns true when the sentry history is too long, either
+// from the configuration or from the Temporal server suggestion.
+func shouldContinueAsNew(ctx workflow.Context, maxHistoryEvents int) bool {
+	info := workflow.GetInfo(ctx)
+	return info.GetCurrentHistoryLength() >= maxHistoryEvents || info.GetContinueAsNewSuggested()
+}
+
+// restoreListeners creates the listeners carried over from a previous run.
+func restoreListeners(ctx workflow.Context, listeners map[string]*listener, states []sentryListenerState) {
+	for _, s := range states {
+		l := newListener(s.RequesterID, s.Callback, s.Signal, s.Delivery, s.RegisteredAt)
+		l.queue = s.Queue
+		l.sampled = s.Sampled
+		l.reference = s.Reference
+		l.dropped = s.Dropped
+		l.delivered = s.Delivered
+		l.failed = s.Failed
+		l.totalDropped = s.TotalDropped
+		l.consecutiveFailures = s.ConsecutiveFailures
+		l.pausedUntil = s.PausedUntil
+
+		listeners[s.RequesterID.String()] = l
+		workflow.Go(ctx, l.routine(listeners))
+	}
+}
+
+// sentryContinueAsNewParams is the state of the sentry run to carry over.
+type sentryContinueAsNewParams struct {
+	Params          ticksSentryWorkflowParams
+	Listeners       map[string]*listener
+	LastTick        *tick.Tick
+	CancelListening workflow.CancelFunc
+	Recorder        *ticksRecorder
+
+	RegisterSignalChannel        workflow.ReceiveChannel
+	UnregisterSignalChannel      workflow.ReceiveChannel
+	NewTickReceivedSignalChannel workflow.ReceiveChannel
+}
+
+// sentryContinueAsNew stops the current sentry run and returns the error
+// that will continue it as new with its listeners, the pending ticks and the
+// ticks that could not be persisted yet.
+func sentryContinueAsNew(ctx workflow.Context, p sentryContinueAsNewParams) error {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("Continuing sentry as new",
+		"history_length", workflow.GetInfo(ctx).GetCurrentHistoryLength(),
+		"listeners_count", len(p.Listeners))
+
+	// Stop listening, the next run will start listening again
+	p.CancelListening()
+
+	// Stop the listeners, wait for the ticks being sent to them and persist
+	// the recorded ones. The ticks still being sent after a while are carried
+	// over to be delivered again by the next run.
+	for _, l := range p.Listeners {
+		l.Stop()
+	}
+	sent, _ := workflow.AwaitWithTimeout(ctx, sentrySendingTimeout, func() bool {
+		for _, l := range p.Listeners {
+			if l.sending {
+				return false
+			}
+		}
+		return true
+	})
+	if !sent {
+		logger.Warn("Listeners still sending ticks, carrying them over", "timeout", sentrySendingTimeout)
+	}
+	unrecorded := p.Recorder.Close(ctx)
+
+	// Drain the signals received in the meantime, without blocking anymore
+	// so that none is received after this point
+	handleListenTicksSignals(ctx, p.Listeners, p.RegisterSignalChannel, p.UnregisterSignalChannel)
+	pending := make([]tick.Tick, 0)
+	var t tick.Tick
+	for p.NewTickReceivedSignalChannel.ReceiveAsync(&t) {
+		pending = append(pending, t)
+	}
+
+	// Carry the listeners over
+	states := make([]sentryListenerState, 0, len(p.Listeners))
+	for _, k := range workflow.DeterministicKeys(p.Listeners) {
+		l := p.Listeners[k]
+		if l.ended {
+			continue
+		}
+		states = append(states, sentryListenerState{
+			RequesterID:  l.requesterID,
+			Callback:     l.callback,
+			Signal:       l.signal,
+			Delivery:     l.delivery,
+			RegisteredAt: l.registeredAt,
+			Queue:        append(append([]tick.Tick{}, l.inFlight...), l.queue...),
+			Sampled:      l.sampled,
+			Reference:    l.reference,
+			Dropped:      l.dropped,
+			Delivered:    l.delivered,
+			Failed:       l.failed,
+			TotalDropped: l.totalDropped,
+
+			ConsecutiveFailures: l.consecutiveFailures,
+			PausedUntil:         l.pausedUntil,
+		})
+	}
+
+	return workflow.NewContinueAsNewError(ctx, ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange:        p.Params.Exchange,
+		Symbol:          p.Params.Symbol,
+		Kind:            p.Params.Kind,
+		Listeners:       states,
+		PendingTicks:    pending,
+		LastTick:        p.LastTick,
+		UnrecordedTicks: unrecorded,
+	})
+}
@@ -0,0 +1,840 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/cryptellation/ticks/svc/db"
+	"github.com/cryptellation/ticks/svc/exchanges"
+	"github.com/cryptellation/ticks/svc/internal/signals"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSentrySuite(t *testing.T) {
+	suite.Run(t, new(SentrySuite))
+}
+
+type SentrySuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+
+	env      *testsuite.TestWorkflowEnvironment
+	received map[uuid.UUID][]tick.Tick
+	batches  map[uuid.UUID][][]tick.Tick
+	dropped  map[uuid.UUID]uint64
+	failures map[uuid.UUID]int
+	ended    map[uuid.UUID]api.SubscriptionEndedCallbackWorkflowParams
+
+	deadLetters  []api.DeadLetter
+	recorded     []tick.Tick
+	databaseDown bool
+}
+
+func (suite *SentrySuite) SetupTest() {
+	ctrl := gomock.NewController(suite.T())
+	wf := &workflows{
+		options:          Options{SentryMaxHistoryEvents: 100},
+		db:               db.NewMockDB(ctrl),
+		exchangesAdapter: exchanges.NewMockExchanges(ctrl),
+	}
+
+	suite.env = suite.NewTestWorkflowEnvironment()
+	suite.env.RegisterWorkflowWithOptions(wf.ticksSentryWorkflow, workflow.RegisterOptions{
+		Name: ticksSentryWorkflowName,
+	})
+
+	// Mock the exchange listening and the database recording
+	suite.env.RegisterActivityWithOptions(wf.exchangesAdapter.ListenSymbolActivity,
+		activity.RegisterOptions{Name: exchanges.ListenSymbolActivityName})
+	suite.env.RegisterActivityWithOptions(wf.db.CreateTicksActivity,
+		activity.RegisterOptions{Name: db.CreateTicksActivityName})
+	suite.env.RegisterActivityWithOptions(wf.db.CreateDeadLetterActivity,
+		activity.RegisterOptions{Name: db.CreateDeadLetterActivityName})
+	suite.env.OnActivity(exchanges.ListenSymbolActivityName, mock.Anything, mock.Anything).
+		Return(exchanges.ListenSymbolResults{}, nil).After(time.Hour)
+
+	// Record the persisted ticks
+	suite.recorded = nil
+	suite.databaseDown = false
+	suite.env.OnActivity(db.CreateTicksActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params db.CreateTicksActivityParams) (db.CreateTicksActivityResults, error) {
+			if suite.databaseDown {
+				return db.CreateTicksActivityResults{}, errors.New("database unavailable")
+			}
+			suite.recorded = append(suite.recorded, params.Ticks...)
+			return db.CreateTicksActivityResults{}, nil
+		}).After(500 * time.Millisecond)
+
+	// Record the failed deliveries
+	suite.deadLetters = nil
+	suite.env.OnActivity(db.CreateDeadLetterActivityName, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, params db.CreateDeadLetterActivityParams) (db.CreateDeadLetterActivityResults, error) {
+			if suite.databaseDown {
+				return db.CreateDeadLetterActivityResults{}, errors.New("database unavailable")
+			}
+			suite.deadLetters = append(suite.deadLetters, params.DeadLetter)
+			return db.CreateDeadLetterActivityResults{ID: int64(len(suite.deadLetters))}, nil
+		})
+
+	// Record the ticks received by the callbacks
+	suite.received = make(map[uuid.UUID][]tick.Tick)
+	suite.batches = make(map[uuid.UUID][][]tick.Tick)
+	suite.dropped = make(map[uuid.UUID]uint64)
+	suite.failures = make(map[uuid.UUID]int)
+	suite.ended = make(map[uuid.UUID]api.SubscriptionEndedCallbackWorkflowParams)
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, params api.ListenToTicksCallbackWorkflowParams) error {
+			suite.received[params.RequesterID] = append(suite.received[params.RequesterID], params.Tick)
+			suite.dropped[params.RequesterID] += params.Dropped
+			return nil
+		}, workflow.RegisterOptions{Name: "Callback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(ctx workflow.Context, params api.ListenToTicksCallbackWorkflowParams) error {
+			suite.received[params.RequesterID] = append(suite.received[params.RequesterID], params.Tick)
+			suite.dropped[params.RequesterID] += params.Dropped
+			return workflow.Sleep(ctx, 10*time.Second)
+		}, workflow.RegisterOptions{Name: "SlowCallback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(ctx workflow.Context, params api.ListenToTicksCallbackWorkflowParams) error {
+			suite.received[params.RequesterID] = append(suite.received[params.RequesterID], params.Tick)
+			suite.dropped[params.RequesterID] += params.Dropped
+			return workflow.Sleep(ctx, 2*api.MaxBlockDuration)
+		}, workflow.RegisterOptions{Name: "StuckCallback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, params api.ListenToTicksBatchCallbackWorkflowParams) error {
+			suite.batches[params.RequesterID] = append(suite.batches[params.RequesterID], params.Ticks)
+			suite.dropped[params.RequesterID] += params.Dropped
+			return nil
+		}, workflow.RegisterOptions{Name: "BatchCallback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, params api.ListenToTicksCallbackWorkflowParams) error {
+			if suite.failures[params.RequesterID] > 0 {
+				suite.failures[params.RequesterID]--
+				return errors.New("failing callback")
+			}
+			suite.received[params.RequesterID] = append(suite.received[params.RequesterID], params.Tick)
+			return nil
+		}, workflow.RegisterOptions{Name: "FlakyCallback"})
+	suite.env.RegisterWorkflowWithOptions(
+		func(_ workflow.Context, params api.SubscriptionEndedCallbackWorkflowParams) error {
+			suite.ended[params.RequesterID] = params
+			return nil
+		}, workflow.RegisterOptions{Name: "EndedCallback"})
+}
+
+func (suite *SentrySuite) tickAt(seconds int) tick.Tick {
+	return tick.FromBook("exchange", "ETH-USDC",
+		time.Date(2024, 1, 1, 0, 0, seconds, 0, time.UTC),
+		float64(seconds), 1, float64(seconds+1), 1)
+}
+
+func (suite *SentrySuite) TestContinueAsNew() {
+	first, second := uuid.New(), uuid.New()
+	callback := runtime.CallbackWorkflow{Name: "Callback"}
+
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SetCurrentHistoryLength(1000)
+		suite.env.SignalWorkflow(signals.RegisterToTicksListeningSignalName,
+			signals.RegisterToTicksListeningSignalParams{RequesterID: second, CallbackWorkflow: callback})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(3))
+	}, 2*time.Second)
+
+	// Start with a listener and a tick carried over from a previous run
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{
+			{RequesterID: first, Callback: callback, Delivered: 10},
+		},
+		PendingTicks: []tick.Tick{suite.tickAt(0)},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+
+	// Check that the sentry continued as new
+	var canErr *workflow.ContinueAsNewError
+	suite.Require().True(errors.As(suite.env.GetWorkflowError(), &canErr))
+	var params ticksSentryWorkflowParams
+	suite.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &params))
+
+	// Check that the carried tick has been sent
+	suite.Require().Len(suite.received[first], 2)
+	suite.Require().Equal(suite.tickAt(0).Time, suite.received[first][0].Time)
+
+	// Check the carried state, with the tick waiting for delivery
+	suite.Require().Equal("ETH-USDC", params.Symbol)
+	suite.Require().Len(params.Listeners, 2)
+	for _, l := range params.Listeners {
+		if l.RequesterID == first {
+			suite.Require().Equal(uint64(12), l.Delivered)
+		} else {
+			suite.Require().Equal(second, l.RequesterID)
+		}
+		suite.Require().Len(l.Queue, 1)
+		suite.Require().Equal(suite.tickAt(2).Time, l.Queue[0].Time)
+	}
+	suite.Require().Len(params.PendingTicks, 1)
+	suite.Require().Equal(suite.tickAt(3).Time, params.PendingTicks[0].Time)
+	suite.Require().NotNil(params.LastTick)
+	suite.Require().Equal(suite.tickAt(2).Time, params.LastTick.Time)
+}
+
+func (suite *SentrySuite) stopOnlyListener(requesterID uuid.UUID, at time.Duration) {
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: requesterID})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, at)
+}
+
+func (suite *SentrySuite) executeWithListener(requesterID uuid.UUID) {
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{
+			{RequesterID: requesterID, Callback: runtime.CallbackWorkflow{Name: "Callback"}},
+		},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+}
+
+func (suite *SentrySuite) TestStopWithoutListeners() {
+	first := uuid.New()
+
+	// Unregister the only listener, then receive a tick while the sentry stops
+	suite.stopOnlyListener(first, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+	}, 1200*time.Millisecond)
+
+	suite.executeWithListener(first)
+	suite.Require().NoError(suite.env.GetWorkflowError())
+}
+
+func (suite *SentrySuite) TestRecordWhenDatabaseIsBack() {
+	first := uuid.New()
+
+	// Receive ticks while the database is unavailable, then stop once it is back
+	suite.databaseDown = true
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(0))
+	}, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.databaseDown = false
+	}, time.Minute)
+	suite.stopOnlyListener(first, 2*time.Minute)
+
+	suite.executeWithListener(first)
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// Check that the ticks received while the database was down are persisted
+	suite.Require().Len(suite.recorded, 2)
+	suite.Require().Equal(suite.tickAt(0).Time, suite.recorded[0].Time)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.recorded[1].Time)
+}
+
+func (suite *SentrySuite) TestContinueAsNewWhileSending() {
+	stuck := uuid.New()
+
+	// Continue as new while the first tick is still being delivered
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SetCurrentHistoryLength(1000)
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+	}, 2*time.Second)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{
+			{RequesterID: stuck, Callback: runtime.CallbackWorkflow{Name: "StuckCallback"}},
+		},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+
+	// Check that the tick being delivered is carried over before the next one
+	var canErr *workflow.ContinueAsNewError
+	suite.Require().True(errors.As(suite.env.GetWorkflowError(), &canErr))
+	var params ticksSentryWorkflowParams
+	suite.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &params))
+	suite.Require().Len(params.Listeners, 1)
+	suite.Require().Len(params.Listeners[0].Queue, 2)
+	suite.Require().Equal(suite.tickAt(1).Time, params.Listeners[0].Queue[0].Time)
+	suite.Require().Equal(suite.tickAt(2).Time, params.Listeners[0].Queue[1].Time)
+}
+
+func (suite *SentrySuite) TestContinueAsNewWithUnrecordedTicks() {
+	first := uuid.New()
+
+	// Start with ticks not recorded by a previous run and continue as new
+	// while the database is still unavailable
+	suite.databaseDown = true
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SetCurrentHistoryLength(1000)
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+	}, 2*time.Second)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{
+			{RequesterID: first, Callback: runtime.CallbackWorkflow{Name: "Callback"}},
+		},
+		UnrecordedTicks: []tick.Tick{suite.tickAt(0)},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+
+	// Check that the ticks not recorded are carried over
+	var canErr *workflow.ContinueAsNewError
+	suite.Require().True(errors.As(suite.env.GetWorkflowError(), &canErr))
+	var params ticksSentryWorkflowParams
+	suite.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &params))
+	suite.Require().Len(params.UnrecordedTicks, 2)
+	suite.Require().Equal(suite.tickAt(0).Time, params.UnrecordedTicks[0].Time)
+	suite.Require().Equal(suite.tickAt(1).Time, params.UnrecordedTicks[1].Time)
+	suite.Require().Empty(suite.recorded)
+}
+
+func (suite *SentrySuite) TestStopWithUnrecordedTicks() {
+	first := uuid.New()
+
+	// Stop while the database is unavailable
+	suite.databaseDown = true
+	suite.stopOnlyListener(first, time.Second)
+
+	suite.executeWithListener(first)
+	suite.Require().ErrorContains(suite.env.GetWorkflowError(), ErrTicksNotRecorded.Error())
+}
+
+func (suite *SentrySuite) TestRegisterWhileStopping() {
+	first, second := uuid.New(), uuid.New()
+
+	// Unregister the only listener, then register a new one while the sentry
+	// is persisting its last ticks before exiting
+	suite.stopOnlyListener(first, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.RegisterToTicksListeningSignalName,
+			signals.RegisterToTicksListeningSignalParams{
+				RequesterID:      second,
+				CallbackWorkflow: runtime.CallbackWorkflow{Name: "Callback"},
+			})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+	}, 1200*time.Millisecond)
+
+	suite.executeWithListener(first)
+
+	// Check that the sentry continued as new with the new listener and the tick
+	var canErr *workflow.ContinueAsNewError
+	suite.Require().True(errors.As(suite.env.GetWorkflowError(), &canErr))
+	var params ticksSentryWorkflowParams
+	suite.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(canErr.Input, &params))
+	suite.Require().Len(params.Listeners, 1)
+	suite.Require().Equal(second, params.Listeners[0].RequesterID)
+	suite.Require().Len(params.PendingTicks, 1)
+	suite.Require().Equal(suite.tickAt(2).Time, params.PendingTicks[0].Time)
+}
+
+func (suite *SentrySuite) TestRegisterAndUnregisterWhileStopping() {
+	first, second := uuid.New(), uuid.New()
+
+	// Register and unregister a listener while the sentry is stopping
+	suite.stopOnlyListener(first, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.RegisterToTicksListeningSignalName,
+			signals.RegisterToTicksListeningSignalParams{
+				RequesterID:      second,
+				CallbackWorkflow: runtime.CallbackWorkflow{Name: "Callback"},
+			})
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: second})
+	}, 1200*time.Millisecond)
+
+	suite.executeWithListener(first)
+	suite.Require().NoError(suite.env.GetWorkflowError())
+}
+
+func (suite *SentrySuite) TestSlowListener() {
+	suite.checkSlowListener(api.DeliveryOptions{BufferSize: 1})
+}
+
+func (suite *SentrySuite) TestSlowListenerWithConflation() {
+	suite.checkSlowListener(api.DeliveryOptions{Conflate: true})
+}
+
+func (suite *SentrySuite) checkSlowListener(delivery api.DeliveryOptions) {
+	slow := uuid.New()
+
+	// Send ticks faster than the callback can process them
+	for i := 1; i <= 5; i++ {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: slow})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: slow,
+			Callback:    runtime.CallbackWorkflow{Name: "SlowCallback"},
+			Delivery:    delivery,
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The listener receives the first tick and the most recent one, and is
+	// told about the dropped ones
+	suite.Require().Len(suite.received[slow], 2)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.received[slow][0].Time)
+	suite.Require().Equal(suite.tickAt(5).Time, suite.received[slow][1].Time)
+	suite.Require().Equal(uint64(3), suite.dropped[slow])
+}
+
+func (suite *SentrySuite) TestBlockingListenerTimeout() {
+	blocking := uuid.New()
+
+	// Send ticks while the callback is stuck for longer than the maximum block
+	for i := 1; i <= 3; i++ {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: blocking})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: blocking,
+			Callback:    runtime.CallbackWorkflow{Name: "StuckCallback"},
+			Delivery:    api.DeliveryOptions{BufferSize: 1, OverflowPolicy: api.OverflowPolicyBlock},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The listener stopped waiting and dropped the oldest buffered tick
+	suite.Require().Len(suite.received[blocking], 2)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.received[blocking][0].Time)
+	suite.Require().Equal(suite.tickAt(3).Time, suite.received[blocking][1].Time)
+	suite.Require().Equal(uint64(1), suite.dropped[blocking])
+}
+
+func (suite *SentrySuite) TestSampledListener() {
+	sampled := uuid.New()
+
+	// Send ticks in two sample intervals, with a gap in the first one
+	for _, i := range []int{1, 2, 4, 5, 20} {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	gap := tick.FromGap("exchange", "ETH-USDC", suite.tickAt(2).Time, suite.tickAt(3).Time)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, gap)
+	}, 3*time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: sampled})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: sampled,
+			Callback:    runtime.CallbackWorkflow{Name: "Callback"},
+			Delivery:    api.DeliveryOptions{SampleInterval: 10 * time.Second, DeliverGaps: true},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The gap is delivered right away, then the last tick of each interval
+	suite.Require().Len(suite.received[sampled], 3)
+	suite.Require().True(suite.received[sampled][0].IsGap())
+	suite.Require().Equal(suite.tickAt(5).Time, suite.received[sampled][1].Time)
+	suite.Require().Equal(suite.tickAt(20).Time, suite.received[sampled][2].Time)
+}
+
+func (suite *SentrySuite) TestSampledListenerWithMinPriceChange() {
+	sampled := uuid.New()
+
+	// Send ticks in two sample intervals, the last one of the second interval
+	// moving enough from the previous tick but not from the delivered one
+	for i, price := range map[int]float64{1: 100, 12: 102, 13: 100.5} {
+		t := tick.FromBook("exchange", "ETH-USDC", suite.tickAt(i).Time, price, 1, price, 1)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: sampled})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: sampled,
+			Callback:    runtime.CallbackWorkflow{Name: "Callback"},
+			Delivery:    api.DeliveryOptions{SampleInterval: 10 * time.Second, MinPriceChange: 1},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The price change is measured from the last delivered tick
+	suite.Require().Len(suite.received[sampled], 2)
+	suite.Require().Equal(100.0, suite.received[sampled][0].Price)
+	suite.Require().Equal(102.0, suite.received[sampled][1].Price)
+}
+
+func (suite *SentrySuite) TestBatchedListener() {
+	batched := uuid.New()
+
+	// Send ticks filling a batch, then ticks waiting for the batch latency
+	for _, i := range []int{1, 2, 3, 10} {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: batched})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: batched,
+			Callback:    runtime.CallbackWorkflow{Name: "BatchCallback"},
+			Delivery: api.DeliveryOptions{
+				Batch: api.BatchOptions{MaxSize: 2, MaxLatency: 5 * time.Second},
+			},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The full batch is delivered right away, then the others on latency
+	suite.Require().Len(suite.batches[batched], 3)
+	suite.Require().Len(suite.batches[batched][0], 2)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.batches[batched][0][0].Time)
+	suite.Require().Equal(suite.tickAt(2).Time, suite.batches[batched][0][1].Time)
+	suite.Require().Len(suite.batches[batched][1], 1)
+	suite.Require().Equal(suite.tickAt(3).Time, suite.batches[batched][1][0].Time)
+	suite.Require().Len(suite.batches[batched][2], 1)
+	suite.Require().Equal(suite.tickAt(10).Time, suite.batches[batched][2][0].Time)
+	suite.Require().Zero(suite.dropped[batched])
+}
+
+func (suite *SentrySuite) TestSignalListener() {
+	signaled := uuid.New()
+
+	// Record the ticks signaled to the consumer workflow, until it disappears
+	var ticks []tick.Tick
+	suite.env.OnSignalExternalWorkflow(mock.Anything, "Consumer", "", api.DefaultTicksSignalName, mock.Anything).
+		Return(nil).Times(2).Run(func(args mock.Arguments) {
+		ticks = append(ticks, args.Get(4).(api.ListenToTicksCallbackWorkflowParams).Tick)
+	})
+	suite.env.OnSignalExternalWorkflow(mock.Anything, "Consumer", "", api.DefaultTicksSignalName, mock.Anything).
+		Return(&temporal.UnknownExternalWorkflowExecutionError{}).Once()
+
+	// Send ticks, the last one after the consumer disappeared
+	for _, i := range []int{1, 2, 3, 10} {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: signaled,
+			Signal:      api.SignalTarget{WorkflowID: "Consumer"},
+			// The retry policy does not apply to signals
+			Delivery: api.DeliveryOptions{Failures: api.FailurePolicy{
+				Retry: api.RetryPolicy{MaxAttempts: 3},
+			}},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The ticks are signaled until the consumer disappears, then the sentry
+	// stops as it has no listener anymore
+	suite.Require().Len(ticks, 2)
+	suite.Require().Equal(suite.tickAt(1).Time, ticks[0].Time)
+	suite.Require().Equal(suite.tickAt(2).Time, ticks[1].Time)
+	suite.Require().Len(suite.deadLetters, 1)
+	suite.Require().Equal("Consumer", suite.deadLetters[0].Signal.WorkflowID)
+	suite.Require().Equal(1, suite.deadLetters[0].Attempts)
+}
+
+func (suite *SentrySuite) TestMultipleListeners() {
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	callback := runtime.CallbackWorkflow{Name: "Callback"}
+
+	// Register listeners while ticks are received, with two of them in the
+	// same batch of signals
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.RegisterToTicksListeningSignalName,
+			signals.RegisterToTicksListeningSignalParams{RequesterID: second, CallbackWorkflow: callback})
+		suite.env.SignalWorkflow(signals.RegisterToTicksListeningSignalName,
+			signals.RegisterToTicksListeningSignalParams{RequesterID: third, CallbackWorkflow: callback})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, time.Second)
+	for _, i := range []int{2, 3} {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		for _, id := range []uuid.UUID{first, second, third} {
+			suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+				signals.UnregisterFromTicksListeningSignalParams{RequesterID: id})
+		}
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(60))
+	}, time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange:  "exchange",
+		Symbol:    "ETH-USDC",
+		Kind:      tick.KindBook,
+		Listeners: []sentryListenerState{{RequesterID: first, Callback: callback}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// Every listener receives every tick
+	for _, id := range []uuid.UUID{first, second, third} {
+		suite.Require().Len(suite.received[id], 3, id.String())
+		for i, t := range suite.received[id] {
+			suite.Require().Equal(suite.tickAt(i+1).Time, t.Time)
+		}
+	}
+}
+
+func (suite *SentrySuite) executeWithFlakyListener(requesterID uuid.UUID, delivery api.DeliveryOptions, ticks ...int) {
+	for _, i := range ticks {
+		t := suite.tickAt(i)
+		suite.env.RegisterDelayedCallback(func() {
+			suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, t)
+		}, time.Duration(i)*time.Second)
+	}
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: requesterID})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(120))
+	}, 2*time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: requesterID,
+			Callback:    runtime.CallbackWorkflow{Name: "FlakyCallback"},
+			Delivery:    delivery,
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+}
+
+func (suite *SentrySuite) TestEvictFailingListener() {
+	failing := uuid.New()
+	suite.failures[failing] = 100
+
+	suite.executeWithFlakyListener(failing, api.DeliveryOptions{
+		Failures:      api.FailurePolicy{MaxConsecutiveFailures: 2},
+		EndedCallback: runtime.CallbackWorkflow{Name: "EndedCallback"},
+	}, 1, 2, 3, 4)
+
+	// The listener is evicted after two failures, and notified
+	suite.Require().Equal(98, suite.failures[failing])
+	suite.Require().Empty(suite.received[failing])
+	ended, ok := suite.ended[failing]
+	suite.Require().True(ok)
+	suite.Require().Equal(api.SubscriptionEndReasonFailures, ended.Reason)
+	suite.Require().Equal("exchange", ended.Exchange)
+	suite.Require().Equal("ETH-USDC", ended.Pair)
+	suite.Require().Equal(tick.KindBook, ended.Kind)
+	suite.Require().Contains(ended.Error, "failing callback")
+
+	// The failed deliveries are recorded
+	suite.Require().Len(suite.deadLetters, 2)
+	for i, dl := range suite.deadLetters {
+		suite.Require().Equal(failing, dl.RequesterID)
+		suite.Require().Equal("FlakyCallback", dl.Callback.Name)
+		suite.Require().Equal(tick.KindBook, dl.Kind)
+		suite.Require().Equal(1, dl.Attempts)
+		suite.Require().Contains(dl.Error, "failing callback")
+		suite.Require().Len(dl.Ticks, 1)
+		suite.Require().Equal(suite.tickAt(i+1).Time, dl.Ticks[0].Time)
+	}
+}
+
+func (suite *SentrySuite) TestRecordDeadLetterWhenDatabaseIsBack() {
+	failing := uuid.New()
+	suite.failures[failing] = 1
+
+	// Fail a delivery while the database is unavailable
+	suite.databaseDown = true
+	suite.env.RegisterDelayedCallback(func() {
+		suite.databaseDown = false
+	}, time.Minute)
+	suite.executeWithFlakyListener(failing, api.DeliveryOptions{}, 1)
+
+	// The failed delivery is recorded once the database is back
+	suite.Require().Len(suite.deadLetters, 1)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.deadLetters[0].Ticks[0].Time)
+	suite.Require().Equal(deliveryWorkflowID("SendTick", failing, []tick.Tick{suite.tickAt(1)}),
+		suite.deadLetters[0].DeliveryID)
+}
+
+func (suite *SentrySuite) TestGiveUpDeadLetterWhenDatabaseIsDown() {
+	failing := uuid.New()
+	suite.failures[failing] = 1
+
+	// Fail a delivery while the database is unavailable for longer than the
+	// dead letter recording, then deliver a tick before the database is back
+	suite.databaseDown = true
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(1))
+	}, time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(2))
+	}, deadLetterRecordTimeout+time.Minute)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.databaseDown = false
+	}, deadLetterRecordTimeout+90*time.Second)
+	suite.env.RegisterDelayedCallback(func() {
+		suite.env.SignalWorkflow(signals.UnregisterFromTicksListeningSignalName,
+			signals.UnregisterFromTicksListeningSignalParams{RequesterID: failing})
+		suite.env.SignalWorkflow(signals.NewTickReceivedSignalName, suite.tickAt(3))
+	}, deadLetterRecordTimeout+2*time.Minute)
+
+	suite.env.ExecuteWorkflow(ticksSentryWorkflowName, ticksSentryWorkflowParams{
+		Exchange: "exchange",
+		Symbol:   "ETH-USDC",
+		Kind:     tick.KindBook,
+		Listeners: []sentryListenerState{{
+			RequesterID: failing,
+			Callback:    runtime.CallbackWorkflow{Name: "FlakyCallback"},
+		}},
+	})
+	suite.Require().True(suite.env.IsWorkflowCompleted())
+	suite.Require().NoError(suite.env.GetWorkflowError())
+
+	// The recording is given up without stalling the listener
+	suite.Require().Empty(suite.deadLetters)
+	suite.Require().Len(suite.received[failing], 1)
+	suite.Require().Equal(suite.tickAt(2).Time, suite.received[failing][0].Time)
+}
+
+func (suite *SentrySuite) TestPauseFailingListener() {
+	paused := uuid.New()
+	suite.failures[paused] = 1
+
+	suite.executeWithFlakyListener(paused, api.DeliveryOptions{
+		Failures: api.FailurePolicy{
+			MaxConsecutiveFailures: 1,
+			Action:                 api.FailureActionPause,
+			PauseDuration:          10 * time.Second,
+		},
+		EndedCallback: runtime.CallbackWorkflow{Name: "EndedCallback"},
+	}, 1, 2, 3, 20)
+
+	// The ticks received during the pause are delivered after it
+	suite.Require().Len(suite.received[paused], 3)
+	suite.Require().Equal(suite.tickAt(2).Time, suite.received[paused][0].Time)
+	suite.Require().Equal(suite.tickAt(3).Time, suite.received[paused][1].Time)
+	suite.Require().Equal(suite.tickAt(20).Time, suite.received[paused][2].Time)
+	suite.Require().Empty(suite.ended)
+}
+
+func (suite *SentrySuite) TestRetryFailingDelivery() {
+	retried := uuid.New()
+	suite.failures[retried] = 2
+
+	suite.executeWithFlakyListener(retried, api.DeliveryOptions{
+		Failures: api.FailurePolicy{
+			MaxConsecutiveFailures: 1,
+			Retry:                  api.RetryPolicy{MaxAttempts: 3, InitialInterval: time.Second},
+		},
+	}, 1, 20)
+
+	// The first tick is delivered on its last attempt, without eviction
+	suite.Require().Len(suite.received[retried], 2)
+	suite.Require().Equal(suite.tickAt(1).Time, suite.received[retried][0].Time)
+	suite.Require().Equal(suite.tickAt(20).Time, suite.received[retried][1].Time)
+	suite.Require().Empty(suite.deadLetters)
+}
+
+func (suite *SentrySuite) TestSentryWorkflowIDPrefix() {
+	name := sentryWorkflowName("binance", "ETH-USDC", tick.KindTrade)
+	suite.Require().True(strings.HasPrefix(name, sentryWorkflowIDPrefix("binance", "ETH-USDC")))
+	suite.Require().True(strings.HasPrefix(name, sentryWorkflowIDPrefix("binance", "")))
+	suite.Require().True(strings.HasPrefix(name, sentryWorkflowIDPrefix("", "ETH-USDC")))
+	suite.Require().False(strings.HasPrefix(name, sentryWorkflowIDPrefix("binance", "BTC-USDC")))
+	suite.Require().False(strings.HasPrefix(name, sentryWorkflowIDPrefix("kraken", "")))
+}
@@ -3,6 +3,7 @@ package svc
 import (
 	exchangesclients "github.com/cryptellation/exchanges/pkg/clients"
 	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/svc/db"
 	"github.com/cryptellation/ticks/svc/exchanges"
 	"github.com/cryptellation/ticks/svc/internal/activities"
 	"go.temporal.io/sdk/client"
@@ -23,20 +24,60 @@ type Ticks interface {
 		ctx workflow.Context,
 		params api.UnregisterFromTicksListeningWorkflowParams,
 	) (api.UnregisterFromTicksListeningWorkflowResults, error)
+
+	GetTicksWorkflow(
+		ctx workflow.Context,
+		params api.GetTicksWorkflowParams,
+	) (api.GetTicksWorkflowResults, error)
+
+	GetLastTickWorkflow(
+		ctx workflow.Context,
+		params api.GetLastTickWorkflowParams,
+	) (api.GetLastTickWorkflowResults, error)
+
+	ListSubscriptionsWorkflow(
+		ctx workflow.Context,
+		params api.ListSubscriptionsWorkflowParams,
+	) (api.ListSubscriptionsWorkflowResults, error)
+
+	ListDeadLettersWorkflow(
+		ctx workflow.Context,
+		params api.ListDeadLettersWorkflowParams,
+	) (api.ListDeadLettersWorkflowResults, error)
+
+	RedeliverDeadLettersWorkflow(
+		ctx workflow.Context,
+		params api.RedeliverDeadLettersWorkflowParams,
+	) (api.RedeliverDeadLettersWorkflowResults, error)
 }
 
 // Check that the workflows implements the Ticks interface.
 var _ Ticks = &workflows{}
 
+// DefaultSentryMaxHistoryEvents is the default history size after which a
+// sentry continues as new.
+const DefaultSentryMaxHistoryEvents = 10000
+
+// Options are the options of the ticks workflows.
+type Options struct {
+	// SentryMaxHistoryEvents is the history size after which a sentry
+	// continues as new. Defaults to DefaultSentryMaxHistoryEvents if empty.
+	SentryMaxHistoryEvents int
+}
+
 type workflows struct {
+	options          Options
+	db               db.DB
 	exchangesAdapter exchanges.Exchanges
 	exchangesSvc     exchangesclients.WfClient
 	activities       *activities.Activities
 }
 
 // New creates a new ticks workflows.
-func New(temporalClient client.Client, exchanges exchanges.Exchanges) Ticks {
+func New(temporalClient client.Client, db db.DB, exchanges exchanges.Exchanges, options Options) Ticks {
 	return &workflows{
+		options:          options,
+		db:               db,
 		exchangesSvc:     exchangesclients.NewWfClient(),
 		exchangesAdapter: exchanges,
 		activities:       activities.NewActivities(temporalClient),
@@ -60,6 +101,23 @@ func (wf *workflows) Register(w worker.Worker) {
 		Name: api.UnregisterFromTicksListeningWorkflowName,
 	})
 
+	w.RegisterWorkflowWithOptions(wf.GetTicksWorkflow, workflow.RegisterOptions{
+		Name: api.GetTicksWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(wf.GetLastTickWorkflow, workflow.RegisterOptions{
+		Name: api.GetLastTickWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(wf.ListSubscriptionsWorkflow, workflow.RegisterOptions{
+		Name: api.ListSubscriptionsWorkflowName,
+	})
+
+	w.RegisterWorkflowWithOptions(wf.ListDeadLettersWorkflow, workflow.RegisterOptions{
+		Name: api.ListDeadLettersWorkflowName,
+	})
+	w.RegisterWorkflowWithOptions(wf.RedeliverDeadLettersWorkflow, workflow.RegisterOptions{
+		Name: api.RedeliverDeadLettersWorkflowName,
+	})
+
 	w.RegisterWorkflowWithOptions(ServiceInfoWorkflow, workflow.RegisterOptions{
 		Name: api.ServiceInfoWorkflowName,
 	})
@@ -4,8 +4,10 @@ import (
 	"errors"
 
 	"github.com/cryptellation/ticks/api"
+	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/cryptellation/ticks/svc/internal/signals"
 	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -25,24 +27,54 @@ func (wf *workflows) UnregisterFromTicksListeningWorkflow(
 		return api.UnregisterFromTicksListeningWorkflowResults{}, errors.New("pair must be provided")
 	}
 
+	if err := params.Kind.Validate(); err != nil {
+		return api.UnregisterFromTicksListeningWorkflowResults{}, err
+	}
+
+	// Unregister from the requested kind only, if specified
+	if params.Kind != "" {
+		err := signalUnregister(ctx, params, params.Kind)
+		return api.UnregisterFromTicksListeningWorkflowResults{}, err
+	}
+
+	// Otherwise unregister from all kinds, skipping sentries that are not running
+	var unknownErr *temporal.UnknownExternalWorkflowExecutionError
+	var signaled bool
+	for _, kind := range tick.Kinds {
+		err := signalUnregister(ctx, params, kind)
+		switch {
+		case err == nil:
+			signaled = true
+		case !errors.As(err, &unknownErr):
+			return api.UnregisterFromTicksListeningWorkflowResults{}, err
+		}
+	}
+
+	// Return an error if no sentry was running
+	if !signaled {
+		return api.UnregisterFromTicksListeningWorkflowResults{}, unknownErr
+	}
+
+	// Return an empty result on success
+	return api.UnregisterFromTicksListeningWorkflowResults{}, nil
+}
+
+func signalUnregister(
+	ctx workflow.Context,
+	params api.UnregisterFromTicksListeningWorkflowParams,
+	kind tick.Kind,
+) error {
 	// Prepare the signal parameters for unregistering
 	signalParams := signals.UnregisterFromTicksListeningSignalParams{
 		RequesterID: params.RequesterID,
 	}
 
 	// Send the unregister signal to the sentry workflow
-	err := workflow.SignalExternalWorkflow(
+	return workflow.SignalExternalWorkflow(
 		ctx,
-		sentryWorkflowName(params.Exchange, params.Pair), // Use the sentry workflow ID
+		sentryWorkflowName(params.Exchange, params.Pair, kind), // Use the sentry workflow ID
 		"", // RunID is empty to target the latest run
 		signals.UnregisterFromTicksListeningSignalName, // Signal name
 		signalParams, // Signal parameters
 	).Get(ctx, nil)
-	if err != nil {
-		// Return an error if signaling fails
-		return api.UnregisterFromTicksListeningWorkflowResults{}, err
-	}
-
-	// Return an empty result on success
-	return api.UnregisterFromTicksListeningWorkflowResults{}, nil
 }
@@ -5,6 +5,9 @@ package test
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/cryptellation/ticks/api"
@@ -16,12 +19,19 @@ import (
 
 // TestListenToTicksWithStopListening tests that StopListeningToTicks stops the listener as expected.
 func (suite *EndToEndSuite) TestListenToTicks() {
-	exchange := "binance"
-	pair := "BTC-USDT"
+	suite.listenToTicksThenStop("binance", "BTC-USDT", "TestListenToTicks")
+}
+
+// TestListenToSimulatedTicks tests the listening on the simulated exchange, without network access.
+func (suite *EndToEndSuite) TestListenToSimulatedTicks() {
+	suite.listenToTicksThenStop("simulated", "BTC-USDT", "TestListenToSimulatedTicks")
+}
+
+func (suite *EndToEndSuite) listenToTicksThenStop(exchange, pair, name string) {
 	count := 0
 
 	// Create a worker
-	tq := "TestListenToTicks-TaskQueue"
+	tq := name + "-TaskQueue"
 	w := worker.New(suite.client.TemporalClient(), tq, worker.Options{})
 	defer w.Stop()
 	go func() {
@@ -30,7 +40,7 @@ func (suite *EndToEndSuite) TestListenToTicks() {
 
 	// Create a new client with user agent
 	client := clients.New(suite.client.TemporalClient(), clients.ClientOptions{
-		UserAgent: "TestListenToTicks",
+		UserAgent: name,
 	})
 
 	// Prepare callback params
@@ -57,6 +67,17 @@ func (suite *EndToEndSuite) TestListenToTicks() {
 	}, 10*time.Minute, time.Second,
 		"count should be greater than 0")
 
+	// Check that the subscription is listed
+	subs, err := client.ListSubscriptions(context.Background(), api.ListSubscriptionsWorkflowParams{
+		Exchange: exchange,
+		Pair:     pair,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(subs.Sentries, 1)
+	suite.Require().True(slices.ContainsFunc(subs.Sentries[0].Subscriptions, func(s api.Subscription) bool {
+		return s.RequesterID == id && s.TaskQueue == tq
+	}), "subscription should be listed")
+
 	// Stop listening
 	err = client.StopListeningToTicks(context.Background(), id, exchange, pair)
 	suite.Require().NoError(err)
@@ -66,3 +87,80 @@ func (suite *EndToEndSuite) TestListenToTicks() {
 	time.Sleep(5 * time.Second)
 	suite.Require().Equal(prevCount, count, "count should not increase after stopping listening")
 }
+
+// TestGetRecordedTicks tests that the ticks received while listening are recorded.
+func (suite *EndToEndSuite) TestGetRecordedTicks() {
+	start := time.Now()
+	suite.listenToTicksThenStop("simulated", "ETH-USDT", "TestGetRecordedTicks")
+
+	// Get the first page of recorded ticks
+	res, err := suite.client.GetTicks(context.Background(), api.GetTicksWorkflowParams{
+		Exchange: "simulated",
+		Pair:     "ETH-USDT",
+		Start:    start,
+		Limit:    1,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.Ticks, 1)
+	suite.Require().NotEmpty(res.NextCursor)
+}
+
+// TestLastTick tests that the last tick is fetched even without any listener.
+func (suite *EndToEndSuite) TestLastTick() {
+	t, err := suite.client.LastTick(context.Background(), "simulated", "ETH-USDT")
+	suite.Require().NoError(err)
+	suite.Require().Equal("simulated", t.Exchange)
+	suite.Require().Equal("ETH-USDT", t.Pair)
+	suite.Require().False(t.Time.IsZero())
+}
+
+// TestDeadLetters tests that the failed deliveries are recorded and can be redelivered.
+func (suite *EndToEndSuite) TestDeadLetters() {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	// Create a worker
+	tq := "TestDeadLetters-TaskQueue"
+	w := worker.New(suite.client.TemporalClient(), tq, worker.Options{})
+	defer w.Stop()
+	go func() {
+		suite.Require().NoError(w.Run(nil))
+	}()
+
+	// Listen to ticks with a failing callback
+	id := uuid.New()
+	err := suite.client.ListenToTicks(context.Background(), clients.ListenerParams{
+		RequesterID: id,
+		Callback: func(_ workflow.Context, _ api.ListenToTicksCallbackWorkflowParams) error {
+			if failing.Load() {
+				return errors.New("failing callback")
+			}
+			return nil
+		},
+		Worker:    w,
+		TaskQueue: tq,
+	}, "simulated", "BTC-USDT")
+	suite.Require().NoError(err)
+
+	// Wait until a failed delivery is recorded
+	var deadLetters []api.DeadLetter
+	suite.Eventually(func() bool {
+		res, err := suite.client.ListDeadLetters(context.Background(), api.ListDeadLettersWorkflowParams{
+			RequesterID: id,
+		})
+		deadLetters = res.DeadLetters
+		return err == nil && len(deadLetters) > 0
+	}, time.Minute, time.Second, "a dead letter should be recorded")
+	suite.Require().Equal("simulated", deadLetters[0].Exchange)
+	suite.Require().Contains(deadLetters[0].Error, "failing callback")
+
+	// Stop listening and redeliver the first failed delivery
+	suite.Require().NoError(suite.client.StopListeningToTicks(context.Background(), id, "simulated", "BTC-USDT"))
+	failing.Store(false)
+	res, err := suite.client.RedeliverDeadLetters(context.Background(), api.RedeliverDeadLettersWorkflowParams{
+		IDs: []int64{deadLetters[0].ID},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(res.DeadLetters, 1)
+	suite.Require().NotNil(res.DeadLetters[0].RedeliveredAt)
+}